@@ -0,0 +1,100 @@
+package main
+
+import (
+	"chat/config"
+	"chat/protos"
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const adminUsage = `usage: chat admin <command>
+  sessions                  list connected users
+  kick <user-id> [reason]   disconnect a user
+  ban <username> [reason]   ban a username and disconnect it
+  announce <text>           send an announcement to everyone
+  stats                     show server statistics`
+
+// adminCommand calls the Admin service with the token from client.admin_token.
+func adminCommand(cfg config.ClientConfig, args []string) error {
+	if len(args) == 0 {
+		return errors.New(adminUsage)
+	}
+	if cfg.AdminToken == "" {
+		return errors.New("admin token missing, set client.admin_token or " + config.EnvName("client.admin_token"))
+	}
+
+	transport, err := config.ClientCredentials(cfg.TLS)
+	if err != nil {
+		return err
+	}
+	conn, err := grpc.Dial(cfg.ServerAddress, grpc.WithTransportCredentials(transport))
+	if err != nil {
+		return fmt.Errorf("did not connect: %w", err)
+	}
+	defer conn.Close()
+	admin := protos.NewAdminClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.RequestTimeout)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, "admin-token", cfg.AdminToken)
+
+	command, rest := args[0], args[1:]
+	switch {
+	case command == "sessions":
+		list, err := admin.ListSessions(ctx, &protos.Empty{})
+		if err != nil {
+			return err
+		}
+		table := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(table, "ID\tUSERNAME\tREGISTERED\tSTREAM AGE\tQUEUE")
+		for _, session := range list.Sessions {
+			streamAge := "-"
+			if session.StreamStarted != nil {
+				streamAge = (time.Duration(session.StreamAgeSeconds) * time.Second).String()
+			}
+			fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%d/%d\n",
+				session.User.Id, session.User.Username,
+				session.Registered.AsTime().Local().Format(time.DateTime),
+				streamAge, session.QueueDepth, session.QueueCapacity)
+		}
+		return table.Flush()
+
+	case command == "kick" && len(rest) > 0:
+		_, err := admin.KickUser(ctx, &protos.KickRequest{UserId: rest[0], Reason: strings.Join(rest[1:], " ")})
+		return err
+
+	case command == "ban" && len(rest) > 0:
+		_, err := admin.BanUsername(ctx, &protos.BanRequest{Username: rest[0], Reason: strings.Join(rest[1:], " ")})
+		return err
+
+	case command == "announce" && len(rest) > 0:
+		_, err := admin.BroadcastAnnouncement(ctx, &protos.AnnouncementRequest{Text: strings.Join(rest, " ")})
+		return err
+
+	case command == "stats":
+		stats, err := admin.GetServerStats(ctx, &protos.Empty{})
+		if err != nil {
+			return err
+		}
+		table := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(table, "started\t%s\n", stats.Started.AsTime().Local().Format(time.DateTime))
+		fmt.Fprintf(table, "uptime\t%s\n", time.Duration(stats.UptimeSeconds)*time.Second)
+		fmt.Fprintf(table, "online users\t%d\n", stats.OnlineUsers)
+		fmt.Fprintf(table, "open streams\t%d\n", stats.OpenStreams)
+		fmt.Fprintf(table, "registrations\t%d\n", stats.Registrations)
+		fmt.Fprintf(table, "messages forwarded\t%d\n", stats.MessagesForwarded)
+		fmt.Fprintf(table, "messages dropped\t%d\n", stats.MessagesDropped)
+		fmt.Fprintf(table, "banned usernames\t%d\n", stats.BannedUsernames)
+		return table.Flush()
+
+	default:
+		return errors.New(adminUsage)
+	}
+}
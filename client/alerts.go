@@ -0,0 +1,159 @@
+package client
+
+import (
+	"chat/config"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// notifyTimeout bounds a hanging notify command.
+	notifyTimeout = 10 * time.Second
+	// previewLength is the number of characters passed to the notify command.
+	previewLength = 80
+)
+
+// Alerts announces messages to conversations that aren't open. Bell and title
+// are written straight to the terminal, so Alerts must be used on the ui
+// goroutine like the screen.
+type Alerts struct {
+	config config.AlertsConfig
+	// terminal is nil when neither bell nor title are enabled
+	terminal io.WriteCloser
+	// notifyArgs is the notify command split into fields, empty when none
+	// is set
+	notifyArgs   []string
+	doNotDisturb bool
+	title        string
+}
+
+// NewAlerts opens the terminal for bell and title, alerts that can't be
+// written are disabled with a warning.
+func NewAlerts(cfg config.AlertsConfig) *Alerts {
+	alerts := &Alerts{config: cfg, notifyArgs: strings.Fields(cfg.NotifyCommand), doNotDisturb: cfg.DoNotDisturb}
+	if cfg.Bell || cfg.Title {
+		tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+		if err != nil {
+			slog.Warn("terminal alerts disabled", "err", err)
+			return alerts
+		}
+		alerts.terminal = tty
+		if cfg.Title {
+			// saved to be restored by Close
+			alerts.write("\x1b[22;0t")
+		}
+	}
+	return alerts
+}
+
+// Message announces a message from sender unless do not disturb is on.
+func (a *Alerts) Message(sender, text string) {
+	if a.doNotDisturb {
+		return
+	}
+	if a.config.Bell {
+		a.write("\a")
+	}
+	if len(a.notifyArgs) > 0 {
+		go a.notify(sender, preview(text))
+	}
+}
+
+// preview shortens a message to its first line.
+func preview(text string) string {
+	line, _, cut := strings.Cut(text, "\n")
+	runes := []rune(line)
+	if len(runes) > previewLength {
+		runes, cut = runes[:previewLength], true
+	}
+	if cut {
+		return string(runes) + "…"
+	}
+	return string(runes)
+}
+
+func (a *Alerts) notify(sender, preview string) {
+	args := a.notifyArgs
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	// clipped, concurrent notifications must not append to the shared fields
+	command := exec.CommandContext(ctx, args[0], append(slices.Clip(args[1:]), sender, preview)...)
+	if output, err := command.CombinedOutput(); err != nil {
+		slog.Warn("notify command failed", "command", args[0], "err", err, "output", strings.TrimSpace(string(output)))
+	}
+}
+
+// SetUnread shows the unread count in the terminal title. Do not disturb
+// gives the terminal its own title back until it is turned off.
+func (a *Alerts) SetUnread(username string, unread int) {
+	if !a.config.Title {
+		return
+	}
+	if a.doNotDisturb {
+		a.RestoreTitle()
+		return
+	}
+	title := "chat: " + username
+	if unread > 0 {
+		title = fmt.Sprintf("(%d) %s", unread, title)
+	}
+	if title == a.title {
+		return
+	}
+	a.title = title
+	// control characters of usernames would end the escape sequence
+	a.write("\x1b]2;" + strings.Map(func(r rune) rune {
+		if r < ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, title) + "\a")
+}
+
+// RestoreTitle gives the terminal its own title back, e.g. after logout.
+func (a *Alerts) RestoreTitle() {
+	if !a.config.Title || a.title == "" {
+		return
+	}
+	a.title = ""
+	// restored and saved again for Close
+	a.write("\x1b[23;0t\x1b[22;0t")
+}
+
+// ToggleDoNotDisturb switches alerts off or back on and reports the new state.
+func (a *Alerts) ToggleDoNotDisturb() bool {
+	a.doNotDisturb = !a.doNotDisturb
+	slog.Info("do not disturb changed", "enabled", a.doNotDisturb)
+	return a.doNotDisturb
+}
+
+func (a *Alerts) DoNotDisturb() bool {
+	return a.doNotDisturb
+}
+
+func (a *Alerts) write(sequence string) {
+	if a.terminal == nil {
+		return
+	}
+	if _, err := io.WriteString(a.terminal, sequence); err != nil {
+		slog.Debug("terminal alert not written", "err", err)
+	}
+}
+
+// Close restores the terminal title.
+func (a *Alerts) Close() error {
+	if a.terminal == nil {
+		return nil
+	}
+	if a.config.Title {
+		a.write("\x1b[23;0t")
+	}
+	return a.terminal.Close()
+}
@@ -0,0 +1,36 @@
+package client
+
+import (
+	"chat/config"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNotifyCommandArguments(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "args")
+	script := filepath.Join(dir, "notify.sh")
+	if err := os.WriteFile(script, []byte(`printf '%s|' "$@" > "`+output+`"`+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	alerts := NewAlerts(config.AlertsConfig{NotifyCommand: "  sh " + script + "  -a chat "})
+	alerts.notify("bob", "hi there")
+	got, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "-a|chat|bob|hi there|"; string(got) != want {
+		t.Errorf("arguments = %q, want %q", got, want)
+	}
+}
+
+func TestBlankNotifyCommand(t *testing.T) {
+	alerts := NewAlerts(config.AlertsConfig{NotifyCommand: " \t "})
+	if len(alerts.notifyArgs) != 0 {
+		t.Fatalf("blank command split into %q", alerts.notifyArgs)
+	}
+	// nothing to run, and nothing to panic on
+	alerts.Message("bob", "hi")
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"log/slog"
+)
+
+func (app *TerminalApp) createBlockedUsersPanel() *tview.List {
+	list := app.blockedList
+	list.SetBorder(true)
+	list.SetTitle(" Blocked users ")
+
+	// ignore Tab key
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyTab {
+			return nil
+		}
+		return event
+	})
+
+	app.showBlockedList()
+	return list
+}
+
+// showBlockedList redraws the blocked users, Enter on an entry unblocks it.
+func (app *TerminalApp) showBlockedList() {
+	list := app.blockedList
+	list.Clear()
+
+	blocked := app.data.BlockedUsers()
+	if len(blocked) == 0 {
+		list.AddItem("nobody blocked", "Ctrl-B on the user list blocks", 0, nil)
+		return
+	}
+
+	for _, user := range blocked {
+		id := user.id
+		list.AddItem(tview.Escape(user.label()), fmt.Sprintf("%.5s, Enter to unblock", id), 0, func() {
+			if err := app.data.UnblockUser(id); err != nil {
+				slog.Warn("unblock failed", "client_id", id, "err", err)
+				app.messageView.AppendNotice(noticeError, "unblock failed: "+tview.Escape(err.Error()))
+				return
+			}
+			app.showBlockedList()
+		})
+	}
+}
+
+func (app *TerminalApp) blockHighlightedUser() {
+	index := app.userList.GetCurrentItem()
+	if index < 0 || index >= len(app.listedUserIds) {
+		return
+	}
+	id := app.listedUserIds[index]
+	if myId, _ := app.data.GetUserId(); id == myId {
+		return
+	}
+
+	if err := app.data.BlockUser(id); err != nil {
+		slog.Warn("block failed", "client_id", id, "err", err)
+		app.messageView.AppendNotice(noticeError, "block failed: "+tview.Escape(err.Error()))
+		return
+	}
+	app.showBlockedList()
+}
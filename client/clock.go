@@ -0,0 +1,111 @@
+package client
+
+import (
+	"chat/config"
+	"fmt"
+	"time"
+)
+
+// Clock formats times in the configured zone and clock format.
+type Clock struct {
+	location   *time.Location
+	twelveHour bool
+	// relative shows message times as "5m ago"
+	relative bool
+}
+
+// NewClock loads the zone of the ui.time config.
+func NewClock(cfg config.TimeConfig) (*Clock, error) {
+	location := time.Local
+	if cfg.Timezone != "" {
+		loaded, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("ui.time.timezone: %w", err)
+		}
+		location = loaded
+	}
+	return &Clock{location: location, twelveHour: cfg.Clock == "12h", relative: cfg.Relative}, nil
+}
+
+// activeClock formats the times of the formatting functions, like activeTheme
+// it is only changed on the ui goroutine.
+var activeClock = &Clock{location: time.Local}
+
+// Time is the time of day, 12h times are padded to the width of " 3:04 PM".
+func (c *Clock) Time(t time.Time) string {
+	if c.twelveHour {
+		return fmt.Sprintf("%8s", t.In(c.location).Format("3:04 PM"))
+	}
+	return t.In(c.location).Format("15:04")
+}
+
+// Date is the day of day separators.
+func (c *Clock) Date(t time.Time) string {
+	return t.In(c.location).Format("Mon 2 Jan 2006")
+}
+
+// Full is the date and time of tooltips and details.
+func (c *Clock) Full(t time.Time) string {
+	if c.twelveHour {
+		return t.In(c.location).Format("2006-01-02 3:04:05 PM MST")
+	}
+	return t.In(c.location).Format("2006-01-02 15:04:05 MST")
+}
+
+// Short is the time for today and the date for older times.
+func (c *Clock) Short(t time.Time) string {
+	if c.SameDay(t, time.Now()) {
+		return c.Time(t)
+	}
+	return t.In(c.location).Format("Jan 2")
+}
+
+// Stamp is the date and time of lists such as search results.
+func (c *Clock) Stamp(t time.Time) string {
+	return t.In(c.location).Format("2006-01-02 ") + c.Time(t)
+}
+
+// SameDay reports whether both times fall on the same day in the zone.
+func (c *Clock) SameDay(a, b time.Time) bool {
+	ay, am, ad := a.In(c.location).Date()
+	by, bm, bd := b.In(c.location).Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// Relative is the time since t, "now", "5m ago", "3h ago" or "2d ago", and
+// the date after a week.
+func (c *Clock) Relative(t time.Time) string {
+	since := time.Since(t)
+	switch {
+	case since < time.Minute:
+		return "now"
+	case since < time.Hour:
+		return fmt.Sprintf("%dm ago", int(since/time.Minute))
+	case since < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(since/time.Hour))
+	case since < 7*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(since/(24*time.Hour)))
+	}
+	return t.In(c.location).Format("Jan 2")
+}
+
+// Message is the time of a chat line, relative times are padded so the
+// messages stay aligned.
+func (c *Clock) Message(t time.Time) string {
+	if c.relative {
+		return fmt.Sprintf("%7s", c.Relative(t))
+	}
+	return c.Time(t)
+}
+
+// ToggleRelative switches message times between clock and relative times, it
+// reports whether they are relative now.
+func (c *Clock) ToggleRelative() bool {
+	c.relative = !c.relative
+	return c.relative
+}
+
+// IsRelative reports whether message times are relative.
+func (c *Clock) IsRelative() bool {
+	return c.relative
+}
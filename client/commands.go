@@ -0,0 +1,186 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"github.com/rivo/tview"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// chatCommand is typed into the message input, starting with a slash.
+type chatCommand struct {
+	usage       string
+	description string
+	run         func(app *TerminalApp, argument string) error
+}
+
+var chatCommands = map[string]chatCommand{
+	"status": {
+		usage:       "/status <text>",
+		description: "set your status message, empty clears it",
+		run: func(app *TerminalApp, argument string) error {
+			if err := app.data.SetStatus(argument); err != nil {
+				return err
+			}
+			app.showUpdatedList()
+			return nil
+		},
+	},
+	"nick": {
+		usage:       "/nick <username>",
+		description: "change your username, conversations continue",
+		run: func(app *TerminalApp, argument string) error {
+			if argument == "" {
+				return errors.New("usage: /nick <username>")
+			}
+			return app.changeNickname(argument, app.data.GetDisplayName())
+		},
+	},
+	"name": {
+		usage:       "/name [display name]",
+		description: "show a display name instead of your username, empty clears it",
+		run: func(app *TerminalApp, argument string) error {
+			username, err := app.data.GetUsername()
+			if err != nil {
+				return err
+			}
+			return app.changeNickname(username, argument)
+		},
+	},
+	"theme": {
+		usage:       "/theme [name]",
+		description: "switch the colour theme, lists the themes without a name",
+		run: func(app *TerminalApp, argument string) error {
+			if argument == "" {
+				for _, name := range app.themes.Names() {
+					marker := "  "
+					if name == app.themes.Current() {
+						marker = "* "
+					}
+					app.printSystemLine(marker + tview.Escape(name))
+				}
+				return nil
+			}
+			return app.applyTheme(argument)
+		},
+	},
+	"dnd": {
+		usage:       "/dnd",
+		description: "toggle do not disturb, silences bell, title and notify command",
+		run: func(app *TerminalApp, _ string) error {
+			app.toggleDoNotDisturb()
+			return nil
+		},
+	},
+	"close": {
+		usage:       "/close",
+		description: "close the conversation tab, the history is kept",
+		run: func(app *TerminalApp, _ string) error {
+			app.closeTab()
+			return nil
+		},
+	},
+	"split": {
+		usage:       "/split",
+		description: "toggle a second conversation side by side",
+		run: func(app *TerminalApp, _ string) error {
+			app.toggleSplit()
+			return nil
+		},
+	},
+	"time": {
+		usage:       "/time",
+		description: "toggle relative message times, e.g. 5m ago",
+		run: func(app *TerminalApp, _ string) error {
+			app.toggleRelativeTime()
+			return nil
+		},
+	},
+	"search": {
+		usage:       "/search [text]",
+		description: "search all conversations, Ctrl-R switches to regex",
+		run: func(app *TerminalApp, argument string) error {
+			app.showSearch(argument)
+			return nil
+		},
+	},
+}
+
+func init() {
+	// registered here, logout leads back to runCommand through the dashboard
+	chatCommands["logout"] = chatCommand{
+		usage:       "/logout",
+		description: "log out and return to the login page",
+		run: func(app *TerminalApp, _ string) error {
+			app.logout()
+			return nil
+		},
+	}
+	// registered here, it lists the map it belongs to
+	chatCommands["help"] = chatCommand{
+		usage:       "/help",
+		description: "list commands",
+		run: func(app *TerminalApp, _ string) error {
+			names := make([]string, 0, len(chatCommands))
+			for name := range chatCommands {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				command := chatCommands[name]
+				app.printSystemLine(fmt.Sprintf("%-24s %s", tview.Escape(command.usage), command.description))
+			}
+			return nil
+		},
+	}
+}
+
+// changeNickname renames the user and redraws the labels showing its name.
+func (app *TerminalApp) changeNickname(username, displayName string) error {
+	if err := app.data.ChangeNickname(username, displayName); err != nil {
+		return err
+	}
+	app.showInfo()
+	app.showUpdatedList()
+	myId, _ := app.data.GetUserId()
+	app.printSystemLine("you are now " + tview.Escape(app.data.GetUserDetails(myId)))
+	return nil
+}
+
+// isCommand reports whether the input is a slash command. A double slash
+// sends the text as a message, see unescapeCommand.
+func isCommand(text string) bool {
+	return strings.HasPrefix(text, "/") && !strings.HasPrefix(text, "//")
+}
+
+func unescapeCommand(text string) string {
+	if strings.HasPrefix(text, "//") {
+		return text[1:]
+	}
+	return text
+}
+
+// runCommand executes a slash command, errors are shown in the chat view.
+func (app *TerminalApp) runCommand(text string) {
+	name, argument, _ := strings.Cut(strings.TrimPrefix(text, "/"), " ")
+	command, ok := chatCommands[name]
+	if !ok {
+		app.printError(fmt.Sprintf("unknown command /%s, try /help", tview.Escape(name)))
+		return
+	}
+	if err := command.run(app, strings.TrimSpace(argument)); err != nil {
+		slog.Warn("command failed", "command", name, "err", err)
+		app.printError(fmt.Sprintf("/%s failed: %s", name, tview.Escape(err.Error())))
+	}
+}
+
+// printSystemLine writes a local, not sent, line into the chat view.
+func (app *TerminalApp) printSystemLine(text string) {
+	app.messageView.AppendNotice(noticeInfo, text)
+}
+
+func (app *TerminalApp) printError(text string) {
+	app.messageView.AppendNotice(noticeError, text)
+}
@@ -1,23 +1,45 @@
 package client
 
 import (
+	"chat/config"
 	"chat/protos"
+	"errors"
+	"fmt"
 	"google.golang.org/protobuf/types/known/timestamppb"
-	"log"
+	"log/slog"
+	"regexp"
+	"slices"
+	"sort"
 	"sync"
+	"time"
 )
 
+// ErrUnknownUser is returned when a message refers to a user missing from the db.
+var ErrUnknownUser = errors.New("user not found in the local db")
+
 type LocalDatabase interface {
 	AddUser(user *protos.User)
-	SaveIncomingMessage(mes protos.DirectMessage) DbMessage
-	SaveOutgoingMessage(clientId string, text string)
+	SaveIncomingMessage(mes protos.DirectMessage) (DbMessage, error)
+	SaveOutgoingMessage(clientId string, text string) error
 	AddNewMessageNotification(string)
+	SetFavourite(username string, favourite bool)
 	ListAllUsers() []User
 	GetUser(clientId string) User
 	DeleteUser(clientId string)
 	GetMessages(clientId string) []DbMessage
+	GetMessagesPage(clientId string, before, limit int) (page []DbMessage, first int)
 	RemoveNotification(clientId string)
 	UserOnline(clientId string) bool
+	AddBlockedUser(user User)
+	RemoveBlockedUser(clientId string)
+	ListBlockedUsers() []User
+	SearchMessages(pattern *regexp.Regexp) []MessageMatch
+	SetAccount(account string)
+	SaveDraft(clientId, text string)
+	Draft(clientId string) string
+	SentMessages(clientId string) []string
+	Clear()
+	Close() error
 }
 
 type DbMessage struct {
@@ -26,10 +48,39 @@ type DbMessage struct {
 	time     *timestamppb.Timestamp
 }
 
+// MessageMatch is a message found by SearchMessages, index is its position in
+// the conversation history.
+type MessageMatch struct {
+	peerId   string
+	username string
+	index    int
+	message  DbMessage
+}
+
 type User struct {
-	id           string
-	username     string
-	notification bool
+	id       string
+	username string
+	// displayName is shown instead of the username when set
+	displayName string
+	status      string
+	// unread counts messages received while the conversation was closed
+	unread int
+	// lastActivity is the time of the last message either way
+	lastActivity time.Time
+	favourite    bool
+
+	online    bool
+	firstSeen time.Time
+	// lastSeen is set when the user goes offline
+	lastSeen time.Time
+}
+
+// label is the name the ui shows for the user.
+func (u User) label() string {
+	if u.displayName != "" {
+		return u.displayName
+	}
+	return u.username
 }
 
 type UserDb struct {
@@ -39,14 +90,21 @@ type UserDb struct {
 
 type InMemoryChatDatabase struct {
 	sync.RWMutex
-	users map[string]*UserDb
+	users   map[string]*UserDb
+	blocked map[string]User
+	// favourites and drafts are kept by account, then by peer username: ids
+	// change on every registration and one client logs in as several users.
+	// account is the one logged in, see SetAccount.
+	account    string
+	favourites map[string]map[string]bool
+	drafts     map[string]map[string]string
 }
 
 func (db *InMemoryChatDatabase) UserOnline(clientId string) bool {
 	db.RLock()
 	defer db.RUnlock()
-	_, ok := db.users[clientId]
-	return ok
+	user, ok := db.users[clientId]
+	return ok && user.online
 }
 
 func (db *InMemoryChatDatabase) RemoveNotification(clientId string) {
@@ -56,64 +114,183 @@ func (db *InMemoryChatDatabase) RemoveNotification(clientId string) {
 	if !ok {
 		return
 	}
-	user.notification = false
-	log.Println("user", user.username, "new message notification removed")
+	user.unread = 0
+	slog.Debug("new message notification removed", "username", user.username)
+}
+
+// NewLocalDatabase creates the database selected by the storage config.
+func NewLocalDatabase(storage config.StorageConfig) (LocalDatabase, error) {
+	switch storage.Backend {
+	case config.StorageMemory:
+		return NewInMemoryChatDatabase(), nil
+	case config.StorageFile:
+		return newFileChatDatabase(storage.Path)
+	default:
+		return nil, fmt.Errorf("unknown client storage backend %q", storage.Backend)
+	}
 }
 
 func NewInMemoryChatDatabase() *InMemoryChatDatabase {
-	return &InMemoryChatDatabase{users: make(map[string]*UserDb)}
+	return &InMemoryChatDatabase{
+		users:      make(map[string]*UserDb),
+		blocked:    make(map[string]User),
+		favourites: make(map[string]map[string]bool),
+		drafts:     make(map[string]map[string]string),
+	}
 }
 
+// AddUser inserts a new user or refreshes the profile of a known one,
+// keeping its message history. A renamed user keeps its draft and pin.
 func (db *InMemoryChatDatabase) AddUser(user *protos.User) {
 	db.Lock()
 	defer db.Unlock()
+	if known, ok := db.users[user.Id]; ok {
+		if known.username != user.Username {
+			db.renameLocked(known.username, user.Username)
+		}
+		known.username = user.Username
+		known.displayName = user.DisplayName
+		known.status = user.Status
+		known.online = true
+		slog.Debug("user updated in the db", "username", user.Username)
+		return
+	}
 	db.users[user.Id] = &UserDb{
 		User: User{
-			id:           user.Id,
-			username:     user.Username,
-			notification: false,
+			id:          user.Id,
+			username:    user.Username,
+			displayName: user.DisplayName,
+			status:      user.Status,
+			online:      true,
+			firstSeen:   time.Now(),
 		},
 		messages: make([]DbMessage, 0, 15),
 	}
-	log.Printf("user <%s> added to the db\n", user.Username)
+	slog.Debug("user added to the db", "username", user.Username)
+}
+
+// renameLocked moves what is kept by username, the caller holds the lock.
+func (db *InMemoryChatDatabase) renameLocked(previous, username string) {
+	if draft, ok := db.drafts[db.account][previous]; ok {
+		db.setDraftLocked(previous, "")
+		db.setDraftLocked(username, draft)
+	}
+	if db.favourites[db.account][previous] {
+		db.setFavouriteLocked(previous, false)
+		db.setFavouriteLocked(username, true)
+	}
+	slog.Debug("user renamed in the db", "previous_username", previous, "username", username)
+}
+
+// setDraftLocked changes a draft of the account, an empty text drops it. The
+// caller holds the lock.
+func (db *InMemoryChatDatabase) setDraftLocked(username, text string) {
+	drafts := db.drafts[db.account]
+	if text == "" {
+		delete(drafts, username)
+		if len(drafts) == 0 {
+			delete(db.drafts, db.account)
+		}
+		return
+	}
+	if drafts == nil {
+		drafts = make(map[string]string)
+		db.drafts[db.account] = drafts
+	}
+	drafts[username] = text
+}
+
+// setFavouriteLocked pins or unpins a user for the account. The caller holds
+// the lock.
+func (db *InMemoryChatDatabase) setFavouriteLocked(username string, favourite bool) {
+	favourites := db.favourites[db.account]
+	if !favourite {
+		delete(favourites, username)
+		if len(favourites) == 0 {
+			delete(db.favourites, db.account)
+		}
+		return
+	}
+	if favourites == nil {
+		favourites = make(map[string]bool)
+		db.favourites[db.account] = favourites
+	}
+	favourites[username] = true
 }
 
+// DeleteUser marks the user offline, the message history stays in the db.
 func (db *InMemoryChatDatabase) DeleteUser(clientId string) {
 	db.Lock()
 	defer db.Unlock()
-	username := db.users[clientId].username
-	delete(db.users, clientId)
-	log.Printf("local data about user <%s> deleted\n", username)
+	user, ok := db.users[clientId]
+	if !ok {
+		return
+	}
+	user.online = false
+	user.lastSeen = time.Now()
+	slog.Debug("user went offline", "username", user.username)
 }
 
+// ListAllUsers returns the online users, favourites first, then by recent
+// activity. Ties are broken by name and id so the order is stable.
 func (db *InMemoryChatDatabase) ListAllUsers() []User {
 	db.RLock()
 	defer db.RUnlock()
 	userList := make([]User, 0, 20)
 	for i := range db.users {
 		u := db.users[i].User
+		if !u.online {
+			continue
+		}
+		u.favourite = db.favourites[db.account][u.username]
 		userList = append(userList, u)
 	}
+	sort.Slice(userList, func(i, j int) bool {
+		a, b := userList[i], userList[j]
+		switch {
+		case a.favourite != b.favourite:
+			return a.favourite
+		case !a.lastActivity.Equal(b.lastActivity):
+			return a.lastActivity.After(b.lastActivity)
+		case a.label() != b.label():
+			return a.label() < b.label()
+		}
+		return a.id < b.id
+	})
 	return userList
 }
 
+func (db *InMemoryChatDatabase) SetFavourite(username string, favourite bool) {
+	db.Lock()
+	defer db.Unlock()
+	db.setFavouriteLocked(username, favourite)
+	slog.Debug("favourite changed", "username", username, "favourite", favourite)
+}
+
 func (db *InMemoryChatDatabase) GetUser(clientId string) User {
 	db.RLock()
 	defer db.RUnlock()
-	if online := db.UserOnline(clientId); !online {
+	user, online := db.users[clientId]
+	if !online {
 		return User{}
 	}
-	return db.users[clientId].User
+	found := user.User
+	found.favourite = db.favourites[db.account][found.username]
+	return found
 }
 
 func (db *InMemoryChatDatabase) AddNewMessageNotification(clientId string) {
 	db.Lock()
 	defer db.Unlock()
-	db.users[clientId].notification = true
-	log.Printf("notification about message from <%s> added\n", db.users[clientId].username)
+	user, ok := db.users[clientId]
+	if !ok {
+		return
+	}
+	user.unread++
+	slog.Debug("new message notification added", "username", user.username, "unread", user.unread)
 }
 
-func (db *InMemoryChatDatabase) SaveIncomingMessage(mes protos.DirectMessage) DbMessage {
+func (db *InMemoryChatDatabase) SaveIncomingMessage(mes protos.DirectMessage) (DbMessage, error) {
 	messageFrom := mes.SenderId
 	newMessageDbObject := DbMessage{
 		incoming: true,
@@ -122,12 +299,17 @@ func (db *InMemoryChatDatabase) SaveIncomingMessage(mes protos.DirectMessage) Db
 	}
 	db.Lock()
 	defer db.Unlock()
-	db.users[messageFrom].messages = append(db.users[messageFrom].messages, newMessageDbObject)
-	log.Printf("saved incoming message '%s' from <%s>\n", mes.Message, db.users[mes.SenderId].username)
-	return newMessageDbObject
+	sender, ok := db.users[messageFrom]
+	if !ok {
+		return DbMessage{}, ErrUnknownUser
+	}
+	sender.messages = append(sender.messages, newMessageDbObject)
+	sender.lastActivity = mes.Time.AsTime()
+	slog.Debug("saved incoming message", "username", sender.username)
+	return newMessageDbObject, nil
 }
 
-func (db *InMemoryChatDatabase) SaveOutgoingMessage(clientId string, text string) {
+func (db *InMemoryChatDatabase) SaveOutgoingMessage(clientId string, text string) error {
 	message := DbMessage{
 		incoming: false,
 		text:     text,
@@ -137,12 +319,14 @@ func (db *InMemoryChatDatabase) SaveOutgoingMessage(clientId string, text string
 	db.Lock()
 	defer db.Unlock()
 
-	if db.users[clientId] == nil {
-		log.Fatalln("could not save outgoing message: client not found")
+	receiver, ok := db.users[clientId]
+	if !ok {
+		return ErrUnknownUser
 	}
-	db.users[clientId].messages = append(db.users[clientId].messages, message)
-	log.Println("sent message saved in the db")
-
+	receiver.messages = append(receiver.messages, message)
+	receiver.lastActivity = message.time.AsTime()
+	slog.Debug("sent message saved in the db", "username", receiver.username)
+	return nil
 }
 
 func (db *InMemoryChatDatabase) GetMessages(clientId string) []DbMessage {
@@ -152,6 +336,133 @@ func (db *InMemoryChatDatabase) GetMessages(clientId string) []DbMessage {
 	if !online {
 		return make([]DbMessage, 0)
 	}
-	log.Printf("loaded message history with <%s>\n", clientId)
+	slog.Debug("loaded message history", "client_id", clientId)
 	return allMessages.messages
 }
+
+func (db *InMemoryChatDatabase) AddBlockedUser(user User) {
+	db.Lock()
+	defer db.Unlock()
+	db.blocked[user.id] = user
+	slog.Debug("user blocked", "username", user.username)
+}
+
+func (db *InMemoryChatDatabase) RemoveBlockedUser(clientId string) {
+	db.Lock()
+	defer db.Unlock()
+	delete(db.blocked, clientId)
+}
+
+func (db *InMemoryChatDatabase) ListBlockedUsers() []User {
+	db.RLock()
+	defer db.RUnlock()
+	blockedList := make([]User, 0, len(db.blocked))
+	for _, u := range db.blocked {
+		blockedList = append(blockedList, u)
+	}
+	sort.Slice(blockedList, func(i, j int) bool {
+		return blockedList[i].username < blockedList[j].username
+	})
+	return blockedList
+}
+
+// GetMessagesPage copies up to limit messages stored before the history index
+// before, a negative index pages from the newest message.
+func (db *InMemoryChatDatabase) GetMessagesPage(clientId string, before, limit int) ([]DbMessage, int) {
+	db.RLock()
+	defer db.RUnlock()
+	user, ok := db.users[clientId]
+	if !ok {
+		return nil, 0
+	}
+	if before < 0 || before > len(user.messages) {
+		before = len(user.messages)
+	}
+	first := max(0, before-limit)
+	return slices.Clone(user.messages[first:before]), first
+}
+
+// SearchMessages scans every conversation, offline peers included, and returns
+// the matches newest first.
+func (db *InMemoryChatDatabase) SearchMessages(pattern *regexp.Regexp) []MessageMatch {
+	db.RLock()
+	defer db.RUnlock()
+	matches := make([]MessageMatch, 0)
+	for id, user := range db.users {
+		for i, message := range user.messages {
+			if !pattern.MatchString(message.text) {
+				continue
+			}
+			matches = append(matches, MessageMatch{
+				peerId:   id,
+				username: user.label(),
+				index:    i,
+				message:  message,
+			})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].message.time.AsTime().After(matches[j].message.time.AsTime())
+	})
+	return matches
+}
+
+// SaveDraft keeps the unsent text of a conversation, an empty text drops it.
+func (db *InMemoryChatDatabase) SaveDraft(clientId, text string) {
+	db.Lock()
+	defer db.Unlock()
+	user, ok := db.users[clientId]
+	if !ok {
+		return
+	}
+	db.setDraftLocked(user.username, text)
+}
+
+func (db *InMemoryChatDatabase) Draft(clientId string) string {
+	db.RLock()
+	defer db.RUnlock()
+	user, ok := db.users[clientId]
+	if !ok {
+		return ""
+	}
+	return db.drafts[db.account][user.username]
+}
+
+// SentMessages returns the texts sent to a user, oldest first.
+func (db *InMemoryChatDatabase) SentMessages(clientId string) []string {
+	db.RLock()
+	defer db.RUnlock()
+	user, ok := db.users[clientId]
+	if !ok {
+		return nil
+	}
+	sent := make([]string, 0)
+	for _, message := range user.messages {
+		if !message.incoming {
+			sent = append(sent, message.text)
+		}
+	}
+	return sent
+}
+
+// SetAccount selects the drafts and favourites of the user logging in, the
+// account is the login username at the server address.
+func (db *InMemoryChatDatabase) SetAccount(account string) {
+	db.Lock()
+	defer db.Unlock()
+	db.account = account
+}
+
+// Clear drops the users, conversations and blocked users of a session. Drafts
+// and favourites stay with the account, which is no longer selected.
+func (db *InMemoryChatDatabase) Clear() {
+	db.Lock()
+	defer db.Unlock()
+	db.users = make(map[string]*UserDb)
+	db.blocked = make(map[string]User)
+	db.account = ""
+}
+
+func (db *InMemoryChatDatabase) Close() error {
+	return nil
+}
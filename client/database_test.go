@@ -0,0 +1,152 @@
+package client
+
+import (
+	"chat/protos"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// login selects the account and adds the peers like a logout and a
+// registration do.
+func login(db LocalDatabase, account string, peers ...*protos.User) {
+	db.Clear()
+	db.SetAccount(account)
+	for _, peer := range peers {
+		db.AddUser(peer)
+	}
+}
+
+func TestDraftsAndFavouritesByAccount(t *testing.T) {
+	db := NewInMemoryChatDatabase()
+	bob := &protos.User{Id: "1", Username: "bob"}
+
+	login(db, "alice@a:1", bob)
+	db.SaveDraft(bob.Id, "alice's draft")
+	db.SetFavourite(bob.Username, true)
+
+	// another account of the same client sees none of it
+	carolsBob := &protos.User{Id: "2", Username: "bob"}
+	login(db, "carol@a:1", carolsBob)
+	if got := db.Draft(carolsBob.Id); got != "" {
+		t.Errorf("carol sees draft %q", got)
+	}
+	if db.GetUser(carolsBob.Id).favourite {
+		t.Error("carol sees alice's favourite")
+	}
+	db.SaveDraft(carolsBob.Id, "carol's draft")
+
+	// nor does the same username at another server
+	login(db, "alice@b:2", bob)
+	if got := db.Draft(bob.Id); got != "" {
+		t.Errorf("alice at another server sees draft %q", got)
+	}
+
+	aliceBob := &protos.User{Id: "3", Username: "bob"}
+	login(db, "alice@a:1", aliceBob)
+	if got := db.Draft(aliceBob.Id); got != "alice's draft" {
+		t.Errorf("alice's draft = %q", got)
+	}
+	if !db.GetUser(aliceBob.Id).favourite {
+		t.Error("alice lost the favourite")
+	}
+}
+
+func TestDraftsFollowPeerRenames(t *testing.T) {
+	db := NewInMemoryChatDatabase()
+	login(db, "alice@a:1", &protos.User{Id: "1", Username: "bob"})
+	db.SaveDraft("1", "hello")
+	db.SetFavourite("bob", true)
+
+	db.AddUser(&protos.User{Id: "1", Username: "robert"})
+	if got := db.Draft("1"); got != "hello" {
+		t.Errorf("draft after rename = %q", got)
+	}
+	if !db.GetUser("1").favourite {
+		t.Error("favourite lost by the rename")
+	}
+	// a newcomer taking the old name gets nothing
+	db.AddUser(&protos.User{Id: "2", Username: "bob"})
+	if got := db.Draft("2"); got != "" || db.GetUser("2").favourite {
+		t.Errorf("newcomer got draft %q or the favourite", got)
+	}
+}
+
+func newTestFileDatabase(t *testing.T, path string) *fileChatDatabase {
+	t.Helper()
+	db, err := newFileChatDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestFileDatabaseSavesDraftsWhenTheyChange(t *testing.T) {
+	defer func(delay time.Duration) { draftSaveDelay = delay }(draftSaveDelay)
+	draftSaveDelay = 10 * time.Millisecond
+	path := filepath.Join(t.TempDir(), "client.json")
+
+	db := newTestFileDatabase(t, path)
+	login(db, "alice@a:1", &protos.User{Id: "1", Username: "bob"})
+	db.SaveDraft("1", "unsent")
+
+	// without Close, as after a crash
+	deadline := time.Now().Add(time.Second)
+	for {
+		content, _ := os.ReadFile(path)
+		if strings.Contains(string(content), "unsent") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("draft not written")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	reopened := newTestFileDatabase(t, path)
+	login(reopened, "alice@a:1", &protos.User{Id: "7", Username: "bob"})
+	if got := reopened.Draft("7"); got != "unsent" {
+		t.Errorf("reopened draft = %q", got)
+	}
+	login(reopened, "carol@a:1", &protos.User{Id: "8", Username: "bob"})
+	if got := reopened.Draft("8"); got != "" {
+		t.Errorf("other account reads draft %q", got)
+	}
+}
+
+func TestFileDatabaseRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "client.json")
+	db := newTestFileDatabase(t, path)
+	login(db, "alice@a:1", &protos.User{Id: "1", Username: "bob"}, &protos.User{Id: "2", Username: "dave"})
+	db.SetFavourite("bob", true)
+	db.SaveDraft("2", "later")
+	login(db, "carol@a:1", &protos.User{Id: "3", Username: "dave"})
+	db.SetFavourite("dave", true)
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened := newTestFileDatabase(t, path)
+	tests := []struct {
+		account   string
+		username  string
+		favourite bool
+		draft     string
+	}{
+		{"alice@a:1", "bob", true, ""},
+		{"alice@a:1", "dave", false, "later"},
+		{"carol@a:1", "bob", false, ""},
+		{"carol@a:1", "dave", true, ""},
+	}
+	for _, test := range tests {
+		login(reopened, test.account, &protos.User{Id: "9", Username: test.username})
+		if got := reopened.GetUser("9").favourite; got != test.favourite {
+			t.Errorf("%s %s: favourite = %v, want %v", test.account, test.username, got, test.favourite)
+		}
+		if got := reopened.Draft("9"); got != test.draft {
+			t.Errorf("%s %s: draft = %q, want %q", test.account, test.username, got, test.draft)
+		}
+	}
+}
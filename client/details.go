@@ -0,0 +1,110 @@
+package client
+
+import (
+	"fmt"
+	"github.com/rivo/tview"
+	"regexp"
+	"time"
+)
+
+// linkPattern finds shared links, the protocol has no file transfer so links
+// are the only attachments a conversation can have.
+var linkPattern = regexp.MustCompile(`https?://[^\s]+`)
+
+// maxListedLinks keeps the details panel readable.
+const maxListedLinks = 10
+
+// ConversationDetails describes the selected user and the conversation with it.
+type ConversationDetails struct {
+	User
+	Incoming    int
+	Outgoing    int
+	LastMessage time.Time
+	Links       []string
+}
+
+func (app *TerminalApp) createDetailsPanel() *tview.TextView {
+	details := app.detailsView
+	details.SetDynamicColors(true)
+	details.SetWrap(true)
+	details.SetBorder(true)
+	details.SetTitle(" Details ")
+
+	// follow the selected user
+	go func() {
+		selectedUserChannel, unsubscribe := app.selectedUserId.Subscribe()
+		defer unsubscribe()
+		done := app.sessionDone
+		for {
+			select {
+			case <-done:
+				return
+			case clientId := <-selectedUserChannel:
+				app.app.QueueUpdateDraw(func() {
+					app.showDetails(clientId)
+				})
+			}
+		}
+	}()
+
+	return details
+}
+
+// formatDetailsTime shows how long ago t was, the full date follows on the
+// next line.
+func formatDetailsTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return activeClock.Relative(t) + "\n  " + tag(activeTheme.Muted) + activeClock.Full(t) + tag(activeTheme.Text)
+}
+
+// showDetails renders the details panel, it must run on the ui goroutine.
+func (app *TerminalApp) showDetails(clientId string) {
+	view := app.detailsView
+	view.Clear()
+
+	details := app.data.ConversationDetails(clientId)
+	if details.id == "" {
+		fmt.Fprint(view, tag(activeTheme.Muted)+"no user selected")
+		return
+	}
+
+	presence := tag(activeTheme.Online) + "online" + tag(activeTheme.Text)
+	if !details.online {
+		presence = tag(activeTheme.Muted) + "offline " + tag(activeTheme.Text) + formatDetailsTime(details.lastSeen)
+	}
+	status := details.status
+	if status == "" {
+		status = tag(activeTheme.Muted) + "no status" + tag(activeTheme.Text)
+	} else {
+		status = tview.Escape(status)
+	}
+
+	fmt.Fprintf(view, "[::b]%s[::-]", tview.Escape(details.label()))
+	if details.displayName != "" {
+		fmt.Fprintf(view, " %s%s%s", tag(activeTheme.Muted), tview.Escape(details.username), tag(activeTheme.Text))
+	}
+	fmt.Fprint(view, "\n")
+	fmt.Fprintf(view, "%s\n\n", status)
+	fmt.Fprintf(view, "id:       %s\n", details.id)
+	fmt.Fprintf(view, "presence: %s\n", presence)
+	fmt.Fprintf(view, "first:    %s\n", formatDetailsTime(details.firstSeen))
+	fmt.Fprintf(view, "last msg: %s\n", formatDetailsTime(details.LastMessage))
+	fmt.Fprintf(view, "messages: %d in, %d out\n\n", details.Incoming, details.Outgoing)
+
+	fmt.Fprintf(view, "shared links (%d)\n", len(details.Links))
+	for i, link := range details.Links {
+		if i == maxListedLinks {
+			fmt.Fprintf(view, "%s... %d more%s\n", tag(activeTheme.Muted), len(details.Links)-maxListedLinks, tag(activeTheme.Text))
+			break
+		}
+		fmt.Fprintf(view, "%s%s%s\n", tag(activeTheme.Link), tview.Escape(link), tag(activeTheme.Text))
+	}
+	view.ScrollToBeginning()
+}
+
+// refreshDetails redraws the panel for the current selection.
+func (app *TerminalApp) refreshDetails() {
+	app.showDetails(app.selectedUserId.Get())
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// defaultEditor is used when $EDITOR is not set.
+const defaultEditor = "vi"
+
+// composeInEditor suspends the ui and edits the draft in $EDITOR, returning
+// the saved text.
+func (app *TerminalApp) composeInEditor(draft string) (string, error) {
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{defaultEditor}
+	}
+
+	file, err := os.CreateTemp("", "chat-message-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(draft)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var runErr error
+	suspended := app.app.Suspend(func() {
+		command := exec.Command(editor[0], append(editor[1:], file.Name())...)
+		command.Stdin, command.Stdout, command.Stderr = os.Stdin, os.Stdout, os.Stderr
+		runErr = command.Run()
+	})
+	if !suspended {
+		return "", errors.New("terminal can't be suspended")
+	}
+	if runErr != nil {
+		return "", fmt.Errorf("%s: %w", editor[0], runErr)
+	}
+
+	content, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+	// editors end the file with a new line
+	return strings.TrimRight(string(content), "\n"), nil
+}
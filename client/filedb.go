@@ -0,0 +1,156 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// fileState is what the file backend keeps across restarts, by account.
+// Conversations are not kept, peers get new ids on every registration.
+type fileState struct {
+	Accounts map[string]accountState `json:"accounts"`
+}
+
+type accountState struct {
+	Drafts     map[string]string `json:"drafts,omitempty"`
+	Favourites []string          `json:"favourites,omitempty"`
+}
+
+// draftSaveDelay gathers the draft changes of typing into one write.
+var draftSaveDelay = time.Second
+
+// fileChatDatabase is the memory database with drafts and favourites loaded
+// from a JSON file at start and written back when they change.
+type fileChatDatabase struct {
+	*InMemoryChatDatabase
+	path string
+
+	// saving keeps an older state from replacing a newer one
+	saving sync.Mutex
+	// pending is the scheduled write of changed drafts
+	pendingMu sync.Mutex
+	pending   *time.Timer
+}
+
+func newFileChatDatabase(path string) (*fileChatDatabase, error) {
+	db := &fileChatDatabase{InMemoryChatDatabase: NewInMemoryChatDatabase(), path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return db, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open client store: %w", err)
+	}
+	var state fileState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("read client store %s: %w", path, err)
+	}
+	for account, saved := range state.Accounts {
+		if len(saved.Drafts) > 0 {
+			db.drafts[account] = make(map[string]string, len(saved.Drafts))
+			for username, draft := range saved.Drafts {
+				db.drafts[account][username] = draft
+			}
+		}
+		if len(saved.Favourites) > 0 {
+			db.favourites[account] = make(map[string]bool, len(saved.Favourites))
+			for _, username := range saved.Favourites {
+				db.favourites[account][username] = true
+			}
+		}
+	}
+	slog.Info("client store loaded", "path", path, "accounts", len(state.Accounts))
+	return db, nil
+}
+
+// SetFavourite is written at once, favourites change rarely.
+func (db *fileChatDatabase) SetFavourite(username string, favourite bool) {
+	db.InMemoryChatDatabase.SetFavourite(username, favourite)
+	if err := db.save(); err != nil {
+		slog.Warn("client store not saved", "path", db.path, "err", err)
+	}
+}
+
+// SaveDraft writes the drafts a moment later, they change with every key
+// press.
+func (db *fileChatDatabase) SaveDraft(clientId, text string) {
+	db.InMemoryChatDatabase.SaveDraft(clientId, text)
+	db.pendingMu.Lock()
+	defer db.pendingMu.Unlock()
+	if db.pending == nil {
+		db.pending = time.AfterFunc(draftSaveDelay, db.savePending)
+	}
+}
+
+func (db *fileChatDatabase) savePending() {
+	db.pendingMu.Lock()
+	db.pending = nil
+	db.pendingMu.Unlock()
+	if err := db.save(); err != nil {
+		slog.Warn("client store not saved", "path", db.path, "err", err)
+	}
+}
+
+// Close writes the drafts that are still pending.
+func (db *fileChatDatabase) Close() error {
+	db.pendingMu.Lock()
+	if db.pending != nil {
+		db.pending.Stop()
+		db.pending = nil
+	}
+	db.pendingMu.Unlock()
+	return db.save()
+}
+
+// save replaces the file, a crash while writing leaves the old one.
+func (db *fileChatDatabase) save() error {
+	db.saving.Lock()
+	defer db.saving.Unlock()
+
+	db.RLock()
+	state := fileState{Accounts: make(map[string]accountState)}
+	for account, drafts := range db.drafts {
+		saved := state.Accounts[account]
+		saved.Drafts = make(map[string]string, len(drafts))
+		for username, draft := range drafts {
+			saved.Drafts[username] = draft
+		}
+		state.Accounts[account] = saved
+	}
+	for account, favourites := range db.favourites {
+		saved := state.Accounts[account]
+		for username := range favourites {
+			saved.Favourites = append(saved.Favourites, username)
+		}
+		sort.Strings(saved.Favourites)
+		state.Accounts[account] = saved
+	}
+	db.RUnlock()
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(db.path), filepath.Base(db.path)+".*")
+	if err != nil {
+		return fmt.Errorf("write client store: %w", err)
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return fmt.Errorf("write client store: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("write client store: %w", err)
+	}
+	return os.Rename(temp.Name(), db.path)
+}
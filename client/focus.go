@@ -5,35 +5,136 @@ import (
 	"sort"
 )
 
+// Focusable panes of the dashboard.
+const (
+	paneUsers    = "users"
+	paneComposer = "composer"
+	paneMessages = "messages"
+	paneBlocked  = "blocked"
+	paneSplit    = "split"
+)
+
+// Iterator walks named elements in priority order, both ways. next and prev
+// wrap around; on an empty iterator they return the zero value.
 type Iterator[T any] interface {
-	addItem(T, int)
+	addItem(name string, element T, priority int)
+	removeItem(name string)
 	getCurrent() T
+	currentName() string
+	focus(name string) (T, bool)
+	find(match func(T) bool) (string, bool)
 	next() T
+	prev() T
 }
 
 type FocusElement struct {
+	name     string
 	priority int
 	element  *tview.Box
 }
 
+// ActiveBoxManager is the focus ring of the dashboard, the lowest priority
+// comes first.
 type ActiveBoxManager struct {
 	guiElements  []FocusElement
 	currentIndex int
 }
 
-func (fn *ActiveBoxManager) addItem(guiElement *tview.Box, priority int) {
-	fn.guiElements = append(fn.guiElements, FocusElement{priority, guiElement})
-	sort.Slice(fn.guiElements, func(i, j int) bool {
+// addItem adds or replaces an element, the current element stays current.
+func (fn *ActiveBoxManager) addItem(name string, guiElement *tview.Box, priority int) {
+	current := fn.currentName()
+	fn.remove(name)
+	fn.guiElements = append(fn.guiElements, FocusElement{name, priority, guiElement})
+	sort.SliceStable(fn.guiElements, func(i, j int) bool {
 		return fn.guiElements[i].priority < fn.guiElements[j].priority
 	})
+	fn.currentIndex = max(0, fn.indexOf(current))
+}
+
+// removeItem drops an element, e.g. of a hidden panel. Removing the current
+// element makes the following one current.
+func (fn *ActiveBoxManager) removeItem(name string) {
+	current := fn.currentName()
+	index := fn.remove(name)
+	if index < 0 || len(fn.guiElements) == 0 {
+		fn.currentIndex = max(0, fn.indexOf(current))
+		return
+	}
+	if name == current {
+		fn.currentIndex = index % len(fn.guiElements)
+	} else {
+		fn.currentIndex = fn.indexOf(current)
+	}
+}
+
+// remove deletes an element without touching currentIndex and returns the
+// index it had.
+func (fn *ActiveBoxManager) remove(name string) int {
+	index := fn.indexOf(name)
+	if index >= 0 {
+		fn.guiElements = append(fn.guiElements[:index], fn.guiElements[index+1:]...)
+	}
+	return index
+}
+
+func (fn *ActiveBoxManager) indexOf(name string) int {
+	for i, element := range fn.guiElements {
+		if element.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// focus makes the named element current.
+func (fn *ActiveBoxManager) focus(name string) (*tview.Box, bool) {
+	index := fn.indexOf(name)
+	if index < 0 {
+		return nil, false
+	}
+	fn.currentIndex = index
+	return fn.guiElements[index].element, true
+}
+
+// find returns the name of the first element that matches, e.g. the pane
+// under the mouse.
+func (fn *ActiveBoxManager) find(match func(*tview.Box) bool) (string, bool) {
+	for _, element := range fn.guiElements {
+		if match(element.element) {
+			return element.name, true
+		}
+	}
+	return "", false
 }
 
 func (fn *ActiveBoxManager) next() *tview.Box {
+	if len(fn.guiElements) == 0 {
+		return nil
+	}
 	nextIndex := (fn.currentIndex + 1) % len(fn.guiElements)
 	fn.currentIndex = nextIndex
 	return fn.guiElements[nextIndex].element
 }
 
+func (fn *ActiveBoxManager) prev() *tview.Box {
+	if len(fn.guiElements) == 0 {
+		return nil
+	}
+	prevIndex := (fn.currentIndex - 1 + len(fn.guiElements)) % len(fn.guiElements)
+	fn.currentIndex = prevIndex
+	return fn.guiElements[prevIndex].element
+}
+
 func (fn *ActiveBoxManager) getCurrent() *tview.Box {
+	if len(fn.guiElements) == 0 {
+		return nil
+	}
 	return fn.guiElements[fn.currentIndex].element
 }
+
+func (fn *ActiveBoxManager) currentName() string {
+	if len(fn.guiElements) == 0 {
+		return ""
+	}
+	return fn.guiElements[fn.currentIndex].name
+}
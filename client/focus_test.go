@@ -0,0 +1,132 @@
+package client
+
+import (
+	"github.com/rivo/tview"
+	"slices"
+	"testing"
+)
+
+type focusItem struct {
+	name     string
+	priority int
+}
+
+func newFocusRing(items ...focusItem) *ActiveBoxManager {
+	ring := &ActiveBoxManager{}
+	for _, item := range items {
+		ring.addItem(item.name, tview.NewBox(), item.priority)
+	}
+	return ring
+}
+
+func ringNames(ring *ActiveBoxManager) []string {
+	names := make([]string, 0, len(ring.guiElements))
+	for _, element := range ring.guiElements {
+		names = append(names, element.name)
+	}
+	return names
+}
+
+func TestFocusAddItemOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []focusItem
+		want  []string
+	}{
+		{"empty", nil, []string{}},
+		{"by priority", []focusItem{{"c", 30}, {"a", 10}, {"b", 20}}, []string{"a", "b", "c"}},
+		{"equal priorities keep insertion order", []focusItem{{"a", 10}, {"b", 10}, {"c", 5}}, []string{"c", "a", "b"}},
+		{"re-adding moves an item", []focusItem{{"a", 10}, {"b", 20}, {"a", 30}}, []string{"b", "a"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ringNames(newFocusRing(test.items...)); !slices.Equal(got, test.want) {
+				t.Errorf("order = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFocusAddItemKeepsCurrent(t *testing.T) {
+	ring := newFocusRing(focusItem{"b", 20}, focusItem{"c", 30})
+	ring.focus("c")
+	ring.addItem("a", tview.NewBox(), 10)
+	if got := ring.currentName(); got != "c" {
+		t.Errorf("current = %q, want c", got)
+	}
+}
+
+func TestFocusRemoveItem(t *testing.T) {
+	items := []focusItem{{"a", 10}, {"b", 20}, {"c", 30}}
+	tests := []struct {
+		name    string
+		focused string
+		removed string
+		want    string
+	}{
+		{"focused moves to the following item", "b", "b", "c"},
+		{"focused last wraps to the first", "c", "c", "a"},
+		{"other item before the focused", "c", "a", "c"},
+		{"other item after the focused", "a", "c", "a"},
+		{"unknown item", "b", "x", "b"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ring := newFocusRing(items...)
+			ring.focus(test.focused)
+			ring.removeItem(test.removed)
+			if got := ring.currentName(); got != test.want {
+				t.Errorf("current = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFocusRemoveLastItem(t *testing.T) {
+	ring := newFocusRing(focusItem{"a", 10})
+	ring.removeItem("a")
+	if ring.getCurrent() != nil || ring.next() != nil || ring.prev() != nil {
+		t.Error("empty ring returned an element")
+	}
+	if got := ring.currentName(); got != "" {
+		t.Errorf("current = %q, want none", got)
+	}
+}
+
+func TestFocusWrapAround(t *testing.T) {
+	tests := []struct {
+		name  string
+		start string
+		step  func(*ActiveBoxManager) *tview.Box
+		want  []string
+	}{
+		{"next", "a", (*ActiveBoxManager).next, []string{"b", "c", "a", "b"}},
+		{"prev", "a", (*ActiveBoxManager).prev, []string{"c", "b", "a", "c"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ring := newFocusRing(focusItem{"a", 10}, focusItem{"b", 20}, focusItem{"c", 30})
+			ring.focus(test.start)
+			for i, want := range test.want {
+				box := test.step(ring)
+				if got := ring.currentName(); got != want {
+					t.Fatalf("step %d: current = %q, want %q", i+1, got, want)
+				}
+				if box != ring.getCurrent() {
+					t.Fatalf("step %d: returned box is not the current one", i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestFocusUnknownName(t *testing.T) {
+	ring := newFocusRing(focusItem{"a", 10}, focusItem{"b", 20})
+	ring.focus("b")
+	if _, ok := ring.focus("x"); ok {
+		t.Error("focus of an unknown name succeeded")
+	}
+	if got := ring.currentName(); got != "b" {
+		t.Errorf("current = %q, want b", got)
+	}
+}
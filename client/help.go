@@ -0,0 +1,61 @@
+package client
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"strings"
+)
+
+const helpPageName = "help"
+
+// fixedKeys are handled by the panes themselves and can't be rebound.
+var fixedKeys = [][2]string{
+	{"a-z", "user list: open a conversation"},
+	{"Ctrl-B", "user list: block the highlighted user"},
+	{"Ctrl-P", "user list: pin the highlighted user to the top"},
+	{"Enter", "blocked users: unblock"},
+	{"Alt-1..9", "switch to a conversation tab"},
+	{"Up/Down, j/k", "chat view: scroll a line"},
+	{"PgUp/PgDn", "chat view: scroll a page"},
+	{"Home/End, g/G", "chat view: first message, follow new ones"},
+	{"Enter", "composer: send"},
+	{"Alt-Enter", "composer: new line"},
+	{"Ctrl-E", "composer: edit in $EDITOR"},
+}
+
+// showHelp opens an overlay listing the active key bindings.
+func (app *TerminalApp) showHelp() {
+	var text strings.Builder
+	text.WriteString("[::b]Key bindings[::-] " + tag(activeTheme.Muted) + "(ui.keys in the config)[-]\n\n")
+	for _, action := range app.keymap.Actions() {
+		fmt.Fprintf(&text, "%s%-16s[-] %s\n", tag(activeTheme.Accent), tview.Escape(app.keymap.Key(action)), actionDescriptions[action])
+	}
+	text.WriteString("\n[::b]Pane keys[::-]\n\n")
+	for _, key := range fixedKeys {
+		fmt.Fprintf(&text, "%s%-16s[-] %s\n", tag(activeTheme.Accent), tview.Escape(key[0]), key[1])
+	}
+	text.WriteString("\n" + tag(activeTheme.Muted) + "/help lists commands, Esc closes[-]")
+
+	view := tview.NewTextView().SetDynamicColors(true).SetText(text.String())
+	view.SetBorder(true).SetTitle(" Help ").SetBorderPadding(1, 1, 2, 2)
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape || event.Key() == tcell.KeyEnter ||
+			event.Key() == tcell.KeyRune && (event.Rune() == 'q' || event.Rune() == '?') {
+			app.closeOverlay()
+			return nil
+		}
+		return event
+	})
+
+	// centered over the dashboard
+	overlay := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(view, 0, 5, true).
+			AddItem(nil, 0, 1, false), 0, 2, true).
+		AddItem(nil, 0, 1, false)
+
+	app.showOverlay(helpPageName, overlay, view, view, true)
+}
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"strings"
+	"unicode/utf8"
+)
+
+// Actions that can be bound in the ui.keys config.
+const (
+	ActionNextPane     = "next_pane"
+	ActionPrevPane     = "prev_pane"
+	ActionNextUnread   = "next_unread"
+	ActionSearch       = "search"
+	ActionQuit         = "quit"
+	ActionHelp         = "help"
+	ActionDnd          = "dnd"
+	ActionCloseTab     = "close_tab"
+	ActionSplit        = "split"
+	ActionLogout       = "logout"
+	ActionRelativeTime = "relative_time"
+)
+
+// actionDescriptions documents the actions in the help overlay.
+var actionDescriptions = map[string]string{
+	ActionNextPane:     "focus the next pane",
+	ActionPrevPane:     "focus the previous pane",
+	ActionNextUnread:   "open the next conversation with unread messages",
+	ActionSearch:       "search all conversations",
+	ActionQuit:         "quit the client",
+	ActionHelp:         "show this help",
+	ActionDnd:          "toggle do not disturb",
+	ActionCloseTab:     "close the conversation tab",
+	ActionSplit:        "show two conversations side by side",
+	ActionLogout:       "log out and return to the login page",
+	ActionRelativeTime: "switch message times between clock and relative times",
+}
+
+// keyCodes maps lower case tcell key names such as "ctrl-f" to their keys.
+var keyCodes = map[string]tcell.Key{}
+
+func init() {
+	for key, name := range tcell.KeyNames {
+		keyCodes[strings.ToLower(name)] = key
+	}
+}
+
+// Keymap resolves key events to actions.
+type Keymap struct {
+	// actions is keyed by the normalized key name
+	actions map[string]string
+	keys    map[string]string
+}
+
+// NewKeymap validates the bindings of the ui.keys config.
+func NewKeymap(bindings map[string]string) (*Keymap, error) {
+	keymap := &Keymap{actions: make(map[string]string), keys: make(map[string]string)}
+	for action, spec := range bindings {
+		if _, ok := actionDescriptions[action]; !ok {
+			return nil, fmt.Errorf("ui.keys: unknown action %q", action)
+		}
+		if spec == "" {
+			continue
+		}
+		name, err := normalizeKey(spec)
+		if err != nil {
+			return nil, fmt.Errorf("ui.keys.%s: %w", action, err)
+		}
+		if other, taken := keymap.actions[name]; taken {
+			return nil, fmt.Errorf("ui.keys: %q is bound to both %s and %s", spec, other, action)
+		}
+		keymap.actions[name] = action
+		keymap.keys[action] = spec
+	}
+	return keymap, nil
+}
+
+// normalizeKey parses "Ctrl-F", "Alt-n", "Backtab" or a single character.
+func normalizeKey(spec string) (string, error) {
+	alt := false
+	rest := spec
+	if len(rest) > 4 && strings.EqualFold(rest[:4], "alt-") {
+		alt, rest = true, rest[4:]
+	}
+
+	var name string
+	if utf8.RuneCountInString(rest) == 1 {
+		name = "rune:" + rest
+	} else if key, ok := keyCodes[strings.ToLower(rest)]; ok {
+		name = tcell.KeyNames[key]
+	} else {
+		return "", fmt.Errorf("unknown key %q", spec)
+	}
+	if alt {
+		name = "alt-" + name
+	}
+	return name, nil
+}
+
+// eventKey names an event like normalizeKey names a binding.
+func eventKey(event *tcell.EventKey) string {
+	var name string
+	if event.Key() == tcell.KeyRune {
+		name = "rune:" + string(event.Rune())
+	} else {
+		name = tcell.KeyNames[event.Key()]
+	}
+	if event.Modifiers()&tcell.ModAlt != 0 {
+		name = "alt-" + name
+	}
+	return name
+}
+
+// Action returns the action bound to the event, or an empty string.
+func (k *Keymap) Action(event *tcell.EventKey) string {
+	return k.actions[eventKey(event)]
+}
+
+// Key returns the key bound to an action as written in the config.
+func (k *Keymap) Key(action string) string {
+	return k.keys[action]
+}
+
+// actionOrder is the order of the help overlay.
+var actionOrder = []string{ActionNextPane, ActionPrevPane, ActionNextUnread, ActionSearch, ActionCloseTab, ActionSplit, ActionRelativeTime, ActionDnd, ActionHelp, ActionLogout, ActionQuit}
+
+// Actions lists the bound actions.
+func (k *Keymap) Actions() []string {
+	actions := make([]string, 0, len(k.keys))
+	for _, action := range actionOrder {
+		if _, bound := k.keys[action]; bound {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// isCharacterKey reports whether the binding types text, such keys are left
+// to focused inputs.
+func isCharacterKey(event *tcell.EventKey) bool {
+	return event.Key() == tcell.KeyRune && event.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) == 0
+}
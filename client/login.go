@@ -0,0 +1,209 @@
+package client
+
+import (
+	"chat/config"
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"strings"
+)
+
+// loginPage is the login form. A profile fills the form, its fields can still
+// be edited before logging in.
+func (app *TerminalApp) loginPage() *tview.Frame {
+	addressInput := tview.NewInputField().SetLabel("Server").SetFieldWidth(40)
+	usernameInput := tview.NewInputField().SetLabel("Username").SetFieldWidth(40)
+	passwordInput := tview.NewInputField().SetLabel("Password").SetFieldWidth(40).SetMaskCharacter('*')
+	tlsCheckbox := tview.NewCheckbox().SetLabel("TLS")
+	statusText := tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignCenter)
+
+	// the tls files of the selected profile are used with the checkbox
+	selected := 0
+	profiles := app.profiles.All()
+	profileDropDown := tview.NewDropDown().SetLabel("Profile")
+	listProfiles := func() {
+		profiles = app.profiles.All()
+		names := make([]string, len(profiles))
+		for i, profile := range profiles {
+			names[i] = profile.Name + " (" + profile.ServerAddress + ")"
+		}
+		profileDropDown.SetOptions(names, func(_ string, index int) {
+			selected = index
+			profile := profiles[index]
+			addressInput.SetText(profile.ServerAddress)
+			usernameInput.SetText(profile.Username)
+			tlsCheckbox.SetChecked(profile.TLS.Enabled)
+		})
+	}
+	listProfiles()
+
+	server := func() (string, config.TLSConfig) {
+		tls := profiles[selected].TLS
+		tls.Enabled = tlsCheckbox.IsChecked()
+		return strings.TrimSpace(addressInput.GetText()), tls
+	}
+	setStatus := func(textColor, text string) {
+		statusText.SetText(tag(textColor) + tview.Escape(text))
+	}
+
+	// the health client is replaced by Connect, not while a check uses it
+	checking := false
+	testConnection := func() {
+		if checking {
+			return
+		}
+		address, tls := server()
+		if err := app.data.Connect(address, tls); err != nil {
+			setStatus(activeTheme.Error, err.Error())
+			return
+		}
+		checking = true
+		setStatus(activeTheme.Muted, "connecting to "+address+"...")
+		go func() {
+			err := app.data.CheckHealth()
+			app.app.QueueUpdateDraw(func() {
+				checking = false
+				if err != nil {
+					setStatus(activeTheme.Error, err.Error())
+					return
+				}
+				setStatus(activeTheme.Online, address+" is serving")
+			})
+		}()
+	}
+
+	login := func() {
+		if checking {
+			return
+		}
+		username := strings.TrimSpace(usernameInput.GetText())
+		if username == "" {
+			setStatus(activeTheme.Error, "username is empty")
+			app.app.SetFocus(usernameInput)
+			return
+		}
+		address, tls := server()
+		if err := app.data.Connect(address, tls); err != nil {
+			setStatus(activeTheme.Error, err.Error())
+			return
+		}
+		if err := app.data.Register(username, passwordInput.GetText()); err != nil {
+			slog.Warn("registration failed", "username", username, "address", address, "err", err)
+			setStatus(activeTheme.Error, status.Convert(err).Message())
+			return
+		}
+		passwordInput.SetText("")
+		statusText.SetText("")
+		app.dashboard = app.dashboardPage()
+		app.pages.AddAndSwitchToPage("dashboard", app.dashboard, true)
+	}
+	// a saved profile is named after username and server, saving the same
+	// pair again updates it
+	saveProfile := func() {
+		address, tls := server()
+		username := strings.TrimSpace(usernameInput.GetText())
+		if address == "" || username == "" {
+			setStatus(activeTheme.Error, "server and username are needed to save a profile")
+			return
+		}
+		profile := config.ServerProfile{Name: username + "@" + address, ServerAddress: address, Username: username, TLS: tls}
+		index, err := app.profiles.Save(profile)
+		if err != nil {
+			slog.Warn("profile not saved", "err", err)
+			setStatus(activeTheme.Error, err.Error())
+			return
+		}
+		listProfiles()
+		profileDropDown.SetCurrentOption(index)
+		setStatus(activeTheme.Online, "saved profile "+profile.Name)
+	}
+	// Enter logs in, the form would move on to the next field
+	passwordInput.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEnter {
+			login()
+			return nil
+		}
+		return event
+	})
+
+	form := tview.NewForm().
+		AddFormItem(profileDropDown).
+		AddFormItem(addressInput).
+		AddFormItem(usernameInput).
+		AddFormItem(passwordInput).
+		AddFormItem(tlsCheckbox).
+		AddButton("Test connection", testConnection).
+		AddButton("Log in", login)
+	if app.profiles.CanSave() {
+		form.AddButton("Save profile", saveProfile)
+	}
+	profileDropDown.SetCurrentOption(0)
+	// the profile is the least edited field
+	form.SetFocus(form.GetFormItemIndex("Username"))
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(form, 13, 0, true).
+		AddItem(statusText, 3, 0, false)
+	flex.SetBorderPadding(0, 0, 3, 3)
+
+	frame := tview.NewFrame(flex).SetBorders(2, 2, 2, 2, 4, 4)
+	app.loginFrame = frame
+	app.loginForm = form
+	app.loginStatus = statusText
+	app.passwordInput = passwordInput
+	restyle(frame)
+	app.styleLoginFrame()
+	return frame
+}
+
+// showLogin returns to the login form, it keeps server and username.
+func (app *TerminalApp) showLogin() {
+	app.passwordInput.SetText("")
+	app.loginStatus.SetText("")
+	app.pages.SwitchToPage("startup")
+	app.app.SetFocus(app.loginForm)
+}
+
+// styleLoginFrame colours what restyle doesn't know about, the frame keeps
+// the colours of its texts.
+func (app *TerminalApp) styleLoginFrame() {
+	app.loginFrame.Clear().
+		AddText("Simple grpc server chat with terminal client", true, tview.AlignLeft, color(activeTheme.Text)).
+		AddText("github.com/olgierdjw", true, tview.AlignCenter, color(activeTheme.TertiaryText)).
+		AddText("Go. Protobuf. gRPC. tview.", true, tview.AlignRight, color(activeTheme.Text))
+}
+
+func (app *TerminalApp) serverUnavailablePage(reason error) *tview.Modal {
+	modal := tview.NewModal()
+	setReason := func(err error) {
+		modal.SetText(fmt.Sprintf("Server unavailable\n\n%s", err.Error()))
+	}
+	setReason(reason)
+
+	modal.AddButtons([]string{"Retry", "Change server", "Quit"})
+	modal.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+		switch buttonLabel {
+		case "Quit":
+			app.app.Stop()
+			return
+		case "Change server":
+			app.pages.RemovePage("unavailable")
+			app.app.SetFocus(app.loginForm)
+			return
+		}
+		modal.SetText("Connecting...")
+		go func() {
+			err := app.data.CheckHealth()
+			app.app.QueueUpdateDraw(func() {
+				if err != nil {
+					setReason(err)
+					return
+				}
+				app.pages.RemovePage("unavailable")
+			})
+		}()
+	})
+	return modal
+}
@@ -0,0 +1,157 @@
+package client
+
+import (
+	"github.com/rivo/tview"
+	"regexp"
+	"strings"
+)
+
+// Markdown spans, only the first matching group of a match is set. Spans
+// don't nest except for the text of bold, italic and links.
+var inlinePattern = regexp.MustCompile("`([^`]+)`" +
+	`|\*\*([^*]+)\*\*` +
+	`|__([^_]+)__` +
+	`|\*([^*\s][^*]*)\*` +
+	`|\b_([^_\s][^_]*)_\b` +
+	`|\[([^\]]+)\]\((https?://[^\s()\[\]]+)\)` +
+	`|(https?://[^\s<>"\[\]]+)`)
+
+// codeTokens matches strings, numbers and words of a code line.
+const codeTokens = `("(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'|` + "`[^`]*`)" +
+	`|\b(\d+(?:\.\d+)?)\b` +
+	`|([A-Za-z_]\w*)`
+
+// Code patterns by comment style, the first group is the comment.
+var (
+	slashCodePattern = regexp.MustCompile(`(//.*$)|` + codeTokens)
+	hashCodePattern  = regexp.MustCompile(`(#.*$)|` + codeTokens)
+	anyCodePattern   = regexp.MustCompile(`(//.*$|#.*$)|` + codeTokens)
+)
+
+// codePatterns picks the comment style of a fence language, other languages
+// accept both styles.
+var codePatterns = map[string]*regexp.Regexp{
+	"go": slashCodePattern, "c": slashCodePattern, "cpp": slashCodePattern,
+	"java": slashCodePattern, "js": slashCodePattern, "javascript": slashCodePattern,
+	"ts": slashCodePattern, "typescript": slashCodePattern, "rust": slashCodePattern,
+	"sh": hashCodePattern, "bash": hashCodePattern, "shell": hashCodePattern,
+	"python": hashCodePattern, "py": hashCodePattern, "ruby": hashCodePattern,
+	"yaml": hashCodePattern, "yml": hashCodePattern, "toml": hashCodePattern,
+}
+
+// codeKeywords is shared by all languages, good enough to tell keywords from
+// names in short snippets.
+var codeKeywords = map[string]bool{}
+
+func init() {
+	for _, keyword := range strings.Fields(`
+		and async await break case catch chan class const continue def defer
+		del do done elif else esac except export false False fi finally fn for from
+		func go if impl import in interface is lambda let map match mut new
+		nil None not null or package pub raise range return select self
+		struct switch then this throw true True try type use var while with yield`) {
+		codeKeywords[keyword] = true
+	}
+}
+
+// renderMarkdown turns message text into tview markup. All user text is
+// escaped, only the tags added here are interpreted.
+func renderMarkdown(text string) string {
+	var (
+		lines    []string
+		inCode   bool
+		language string
+	)
+	for _, line := range strings.Split(text, "\n") {
+		fence, isFence := strings.CutPrefix(strings.TrimSpace(line), "```")
+		if isFence && !inCode && len(fence) > 3 && strings.HasSuffix(fence, "```") {
+			// a whole block on one line is inline code
+			lines = append(lines, tag(activeTheme.Code)+tview.Escape(strings.TrimSuffix(fence, "```"))+"[-]")
+			continue
+		}
+		if isFence {
+			inCode = !inCode
+			if inCode {
+				language = strings.ToLower(strings.TrimSpace(fence))
+				if language != "" {
+					lines = append(lines, tag(activeTheme.Muted)+tview.Escape(language)+"[-]")
+				}
+			}
+			continue
+		}
+		if inCode {
+			lines = append(lines, tag(activeTheme.Muted)+"│[-] "+highlightCode(line, language))
+		} else {
+			lines = append(lines, renderInline(line))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func renderInline(text string) string {
+	var rendered strings.Builder
+	last := 0
+	for _, match := range inlinePattern.FindAllStringSubmatchIndex(text, -1) {
+		rendered.WriteString(tview.Escape(text[last:match[0]]))
+		last = match[1]
+		group := func(n int) string {
+			return text[match[2*n]:match[2*n+1]]
+		}
+		switch {
+		case match[2] >= 0:
+			rendered.WriteString(tag(activeTheme.Code) + tview.Escape(group(1)) + "[-]")
+		case match[4] >= 0:
+			rendered.WriteString("[::b]" + renderInline(group(2)) + "[::B]")
+		case match[6] >= 0:
+			rendered.WriteString("[::b]" + renderInline(group(3)) + "[::B]")
+		case match[8] >= 0:
+			rendered.WriteString("[::i]" + renderInline(group(4)) + "[::I]")
+		case match[10] >= 0:
+			rendered.WriteString("[::i]" + renderInline(group(5)) + "[::I]")
+		case match[12] >= 0:
+			rendered.WriteString(renderLink(renderInline(group(6)), group(7)))
+			rendered.WriteString(" " + tag(activeTheme.Muted) + "<" + tview.Escape(group(7)) + ">[-]")
+		case match[16] >= 0:
+			rendered.WriteString(renderLink(tview.Escape(group(8)), group(8)))
+		}
+	}
+	rendered.WriteString(tview.Escape(text[last:]))
+	return rendered.String()
+}
+
+// renderLink underlines the label and attaches the url, terminals supporting
+// hyperlinks make it clickable. The patterns keep brackets out of urls.
+func renderLink(label, url string) string {
+	return tag(activeTheme.Link+"::u:"+url) + label + "[-::U:-]"
+}
+
+func highlightCode(line, language string) string {
+	pattern, ok := codePatterns[language]
+	if !ok {
+		pattern = anyCodePattern
+	}
+
+	var rendered strings.Builder
+	last := 0
+	for _, match := range pattern.FindAllStringSubmatchIndex(line, -1) {
+		token := line[match[0]:match[1]]
+		var tokenColor string
+		switch {
+		case match[2] >= 0:
+			tokenColor = activeTheme.Comment
+		case match[4] >= 0:
+			tokenColor = activeTheme.String
+		case match[6] >= 0:
+			tokenColor = activeTheme.Number
+		case codeKeywords[token]:
+			tokenColor = activeTheme.Keyword
+		default:
+			continue
+		}
+		rendered.WriteString(tag(activeTheme.CodeText) + tview.Escape(line[last:match[0]]))
+		rendered.WriteString(tag(tokenColor) + tview.Escape(token))
+		last = match[1]
+	}
+	rendered.WriteString(tag(activeTheme.CodeText) + tview.Escape(line[last:]) + "[-]")
+	return rendered.String()
+}
@@ -0,0 +1,490 @@
+package client
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// messagePageSize is how many messages are loaded from the db at once.
+const messagePageSize = 50
+
+// wheelLines is how far a mouse wheel step scrolls.
+const wheelLines = 3
+
+// styleTagPattern matches tview style tags, "[]" is the escape marker and is
+// skipped by the caller.
+var styleTagPattern = regexp.MustCompile(`\[([a-zA-Z]*|#[0-9a-zA-Z]*|-)?(:([a-zA-Z]*|#[0-9a-zA-Z]*|-)?(:([bdilrsuBDILRSU]+|-)?(:[^\[\]]*)?)?)?\]`)
+
+// MessagePageLoader returns up to limit messages of a conversation that come
+// before the history index before (a negative value loads the newest page),
+// together with the history index of the first returned message.
+type MessagePageLoader func(clientId string, before, limit int) ([]DbMessage, int)
+
+type messageEntry struct {
+	// message is nil for local notices
+	message *DbMessage
+	notice  string
+	kind    noticeKind
+	// marked highlights a search result
+	marked bool
+	// dayBreak puts a day separator above the first message of a day
+	dayBreak bool
+
+	// wrapped lines for the width they were wrapped for
+	lines []string
+	width int
+}
+
+type scrollPosition struct {
+	offset int
+	// loaded is the history length when the position was saved
+	loaded int
+}
+
+// MessageView shows one conversation. Only the visible window is wrapped and
+// drawn, older pages are loaded from the db when scrolling reaches the top.
+type MessageView struct {
+	*tview.Box
+
+	loadPage MessagePageLoader
+
+	peerId  string
+	entries []*messageEntry
+	// firstIndex is the history index of the first loaded message
+	firstIndex int
+	// historyLength counts the messages of the conversation, loaded or not
+	historyLength int
+
+	// offset is the number of lines scrolled up from the bottom
+	offset    int
+	positions map[string]scrollPosition
+	height    int
+	// rows are the entries of the drawn lines, for mouse clicks
+	rows []*messageEntry
+	// hoverRow is the row under the mouse pointer, -1 when it is outside
+	hoverRow int
+
+	contextMenu func(peerId string, message DbMessage, x, y int)
+}
+
+func NewMessageView(loadPage MessagePageLoader) *MessageView {
+	return &MessageView{
+		Box:       tview.NewBox(),
+		loadPage:  loadPage,
+		positions: make(map[string]scrollPosition),
+		hoverRow:  -1,
+	}
+}
+
+// SetContextMenuFunc sets the handler of right clicks on messages, peerId is
+// the conversation shown and x and y are the screen position of the click.
+func (v *MessageView) SetContextMenuFunc(handler func(peerId string, message DbMessage, x, y int)) *MessageView {
+	v.contextMenu = handler
+	return v
+}
+
+// ShowConversation switches to another peer, remembering where the previous
+// conversation was scrolled to.
+func (v *MessageView) ShowConversation(peerId string) {
+	if peerId == v.peerId {
+		return
+	}
+	if v.peerId != "" {
+		v.positions[v.peerId] = scrollPosition{offset: v.offset, loaded: v.historyLength}
+	}
+
+	v.peerId = peerId
+	v.entries = v.entries[:0]
+	page, first := v.loadPage(peerId, -1, messagePageSize)
+	v.firstIndex = first
+	v.historyLength = first + len(page)
+	for i := range page {
+		v.entries = append(v.entries, &messageEntry{message: &page[i]})
+	}
+	v.markDays()
+
+	v.offset = 0
+	if position, ok := v.positions[peerId]; ok && position.offset > 0 {
+		// keep the same lines in sight even if messages arrived meanwhile
+		v.offset = position.offset + v.linesSince(position.loaded)
+	}
+}
+
+// ShowMessage opens a conversation scrolled to the message at the history
+// index, the message stays marked until the conversation is switched.
+func (v *MessageView) ShowMessage(peerId string, historyIndex int) {
+	v.ShowConversation(peerId)
+	for v.firstIndex > historyIndex && v.loadOlder() {
+	}
+
+	for _, entry := range v.entries {
+		if entry.marked {
+			entry.marked, entry.lines = false, nil
+		}
+	}
+	entryIndex := v.entryIndex(historyIndex)
+	if entryIndex < 0 {
+		return
+	}
+	entry := v.entries[entryIndex]
+	entry.marked, entry.lines = true, nil
+
+	// the message goes to the top of the view
+	v.offset = max(0, v.linesSince(historyIndex)-v.height)
+}
+
+// entryIndex finds the loaded entry of a history index, notices are skipped.
+func (v *MessageView) entryIndex(historyIndex int) int {
+	index := v.firstIndex
+	for i, entry := range v.entries {
+		if entry.message == nil {
+			continue
+		}
+		if index == historyIndex {
+			return i
+		}
+		index++
+	}
+	return -1
+}
+
+// linesSince counts the wrapped lines from a history index to the bottom.
+func (v *MessageView) linesSince(historyIndex int) int {
+	_, _, width, _ := v.GetInnerRect()
+	lines := 0
+	index := v.historyLength
+	for i := len(v.entries) - 1; i >= 0; i-- {
+		if v.entries[i].message != nil {
+			index--
+			if index < historyIndex {
+				break
+			}
+		}
+		lines += len(v.wrap(v.entries[i], width))
+	}
+	return lines
+}
+
+// AppendMessage adds a message of the shown conversation at the bottom.
+func (v *MessageView) AppendMessage(message DbMessage) {
+	v.historyLength++
+	v.append(&messageEntry{message: &message})
+}
+
+// noticeKind picks the theme colour of a notice.
+type noticeKind int
+
+const (
+	noticeInfo noticeKind = iota
+	noticeWarning
+	noticeError
+)
+
+// AppendNotice adds a local line, notices are not stored and disappear when
+// switching conversations.
+func (v *MessageView) AppendNotice(kind noticeKind, text string) {
+	v.append(&messageEntry{notice: text, kind: kind})
+}
+
+// Invalidate drops the wrapped lines, e.g. after the theme changed.
+func (v *MessageView) Invalidate() {
+	for _, entry := range v.entries {
+		entry.lines = nil
+	}
+}
+
+func (v *MessageView) append(entry *messageEntry) {
+	if entry.message != nil {
+		entry.dayBreak = true
+		for i := len(v.entries) - 1; i >= 0; i-- {
+			if previous := v.entries[i].message; previous != nil {
+				entry.dayBreak = !activeClock.SameDay(previous.time.AsTime(), entry.message.time.AsTime())
+				break
+			}
+		}
+	}
+	// stay on the same lines when scrolled up
+	if v.offset > 0 {
+		_, _, width, _ := v.GetInnerRect()
+		v.offset += len(v.wrap(entry, width))
+	}
+	v.entries = append(v.entries, entry)
+}
+
+// loadOlder prepends the previous page, it reports false at the beginning of
+// the history.
+func (v *MessageView) loadOlder() bool {
+	if v.firstIndex == 0 || v.peerId == "" {
+		return false
+	}
+	page, first := v.loadPage(v.peerId, v.firstIndex, messagePageSize)
+	if len(page) == 0 {
+		return false
+	}
+	older := make([]*messageEntry, 0, len(page)+len(v.entries))
+	for i := range page {
+		older = append(older, &messageEntry{message: &page[i]})
+	}
+	v.entries = append(older, v.entries...)
+	v.firstIndex = first
+	v.markDays()
+	return true
+}
+
+// markDays flags the first message of every day. The first loaded message is
+// only flagged at the beginning of the history, the day before it is unknown.
+func (v *MessageView) markDays() {
+	var previous *DbMessage
+	for _, entry := range v.entries {
+		if entry.message == nil {
+			continue
+		}
+		dayBreak := v.firstIndex == 0
+		if previous != nil {
+			dayBreak = !activeClock.SameDay(previous.time.AsTime(), entry.message.time.AsTime())
+		}
+		if dayBreak != entry.dayBreak {
+			entry.dayBreak, entry.lines = dayBreak, nil
+		}
+		previous = entry.message
+	}
+}
+
+func (v *MessageView) ScrollUp(lines int) {
+	v.offset += lines
+}
+
+func (v *MessageView) ScrollDown(lines int) {
+	v.offset = max(0, v.offset-lines)
+}
+
+func (v *MessageView) ScrollToEnd() {
+	v.offset = 0
+}
+
+func (v *MessageView) ScrollToBeginning() {
+	for v.loadOlder() {
+	}
+	_, _, width, _ := v.GetInnerRect()
+	total := 0
+	for _, entry := range v.entries {
+		total += len(v.wrap(entry, width))
+	}
+	v.offset = max(0, total-v.height)
+}
+
+func (v *MessageView) wrap(entry *messageEntry, width int) []string {
+	if entry.lines != nil && entry.width == width {
+		return entry.lines
+	}
+	var lines []string
+	if entry.message != nil {
+		prefix, text := formatMessage(entry.message)
+		lines = hangingIndent(prefix, text, width)
+	} else {
+		lines = carryStyleTags(tview.WordWrap(formatNotice(entry.kind, entry.notice), max(width, 1)))
+	}
+	if entry.marked {
+		for i := range lines {
+			lines[i] = "[::r]" + lines[i] + "[::-]"
+		}
+	}
+	if entry.dayBreak {
+		lines = append([]string{daySeparator(entry.message.time.AsTime(), width)}, lines...)
+	}
+	entry.lines = lines
+	entry.width = width
+	return entry.lines
+}
+
+// daySeparator is the centred date line above the first message of a day.
+func daySeparator(t time.Time, width int) string {
+	label := "── " + activeClock.Date(t) + " ──"
+	padding := max(0, (width-tview.TaggedStringWidth(label))/2)
+	return tag(activeTheme.Muted) + strings.Repeat(" ", padding) + label + tag(activeTheme.Text)
+}
+
+// minTextWidth is the narrowest text column worth indenting under a prefix.
+const minTextWidth = 12
+
+// hangingIndent wraps text next to the prefix and aligns the following lines
+// with the first one.
+func hangingIndent(prefix, text string, width int) []string {
+	indent := tview.TaggedStringWidth(prefix)
+	if width-indent < minTextWidth {
+		return carryStyleTags(tview.WordWrap(prefix+text, max(width, 1)))
+	}
+	lines := carryStyleTags(tview.WordWrap(text, width-indent))
+	padding := strings.Repeat(" ", indent)
+	for i := range lines {
+		if i == 0 {
+			lines[i] = prefix + lines[i]
+		} else {
+			lines[i] = padding + lines[i]
+		}
+	}
+	return lines
+}
+
+// carryStyleTags repeats the tags of earlier lines at the start of every
+// wrapped line, so colours survive the line breaks.
+func carryStyleTags(lines []string) []string {
+	var active strings.Builder
+	for i, line := range lines {
+		prefix := active.String()
+		for _, tag := range styleTagPattern.FindAllString(line, -1) {
+			if tag != "[]" {
+				active.WriteString(tag)
+			}
+		}
+		lines[i] = prefix + line
+	}
+	return lines
+}
+
+// visibleLines wraps messages from the bottom up until the window is filled,
+// loading older pages when the loaded ones are not enough.
+func (v *MessageView) visibleLines(width, height int) ([]string, []*messageEntry) {
+	needed := height + v.offset
+	reversed := make([]string, 0, needed)
+	reversedEntries := make([]*messageEntry, 0, needed)
+	index := len(v.entries) - 1
+	for len(reversed) < needed {
+		if index < 0 {
+			before := len(v.entries)
+			if !v.loadOlder() {
+				break
+			}
+			index = len(v.entries) - before - 1
+		}
+		lines := v.wrap(v.entries[index], width)
+		for i := len(lines) - 1; i >= 0; i-- {
+			reversed = append(reversed, lines[i])
+			reversedEntries = append(reversedEntries, v.entries[index])
+		}
+		index--
+	}
+
+	// the top of the history limits scrolling
+	v.offset = max(0, min(v.offset, len(reversed)-height))
+
+	end := min(len(reversed), v.offset+height)
+	visible := make([]string, 0, height)
+	entries := make([]*messageEntry, 0, height)
+	for i := end - 1; i >= v.offset; i-- {
+		visible = append(visible, reversed[i])
+		entries = append(entries, reversedEntries[i])
+	}
+	return visible, entries
+}
+
+func (v *MessageView) Draw(screen tcell.Screen) {
+	v.Box.DrawForSubclass(screen, v)
+	x, y, width, height := v.GetInnerRect()
+	if width <= 0 || height <= 0 {
+		return
+	}
+	v.height = height
+
+	lines, rows := v.visibleLines(width, height)
+	v.rows = rows
+	for row, line := range lines {
+		tview.Print(screen, line, x, y+row, width, tview.AlignLeft, tview.Styles.PrimaryTextColor)
+	}
+
+	if v.offset > 0 {
+		tview.Print(screen, activeTheme.highlightTag()+" more below, End to return [-:-]", x, y+height-1, width, tview.AlignRight, tview.Styles.PrimaryTextColor)
+	}
+
+	// the tooltip goes above the hovered line, below it on the first row
+	if hovered := v.hovered(); hovered != nil {
+		row := v.hoverRow - 1
+		if row < 0 {
+			row = v.hoverRow + 1
+		}
+		messageTime := hovered.message.time.AsTime()
+		tooltip := activeTheme.highlightTag() + " " + activeClock.Full(messageTime) + ", " + activeClock.Relative(messageTime) + " [-:-]"
+		if row < height {
+			tview.Print(screen, tooltip, x, y+row, width, tview.AlignRight, tview.Styles.PrimaryTextColor)
+		}
+	}
+}
+
+// Hover follows the mouse pointer, it reports whether the hovered message
+// changed and the view needs to be drawn.
+func (v *MessageView) Hover(x, y int) bool {
+	before := v.hovered()
+	_, top, _, _ := v.GetInnerRect()
+	v.hoverRow = -1
+	if v.InRect(x, y) {
+		v.hoverRow = y - top
+	}
+	return v.hovered() != before
+}
+
+// hovered returns the message under the mouse pointer, or nil.
+func (v *MessageView) hovered() *messageEntry {
+	if v.hoverRow < 0 || v.hoverRow >= len(v.rows) || v.rows[v.hoverRow].message == nil {
+		return nil
+	}
+	return v.rows[v.hoverRow]
+}
+
+func (v *MessageView) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return v.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+		page := max(1, v.height-1)
+		switch event.Key() {
+		case tcell.KeyUp:
+			v.ScrollUp(1)
+		case tcell.KeyDown:
+			v.ScrollDown(1)
+		case tcell.KeyPgUp:
+			v.ScrollUp(page)
+		case tcell.KeyPgDn:
+			v.ScrollDown(page)
+		case tcell.KeyHome:
+			v.ScrollToBeginning()
+		case tcell.KeyEnd:
+			v.ScrollToEnd()
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'k':
+				v.ScrollUp(1)
+			case 'j':
+				v.ScrollDown(1)
+			case 'g':
+				v.ScrollToBeginning()
+			case 'G':
+				v.ScrollToEnd()
+			}
+		}
+	})
+}
+
+func (v *MessageView) MouseHandler() func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+	return v.WrapMouseHandler(func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+		x, y := event.Position()
+		if !v.InRect(x, y) {
+			return false, nil
+		}
+		switch action {
+		case tview.MouseLeftDown:
+			setFocus(v)
+		case tview.MouseScrollUp:
+			v.ScrollUp(wheelLines)
+		case tview.MouseScrollDown:
+			v.ScrollDown(wheelLines)
+		case tview.MouseRightClick:
+			_, top, _, _ := v.GetInnerRect()
+			if row := y - top; row >= 0 && row < len(v.rows) && v.rows[row].message != nil && v.contextMenu != nil {
+				v.contextMenu(v.peerId, *v.rows[row].message, x, y)
+			}
+		default:
+			return false, nil
+		}
+		return true, nil
+	})
+}
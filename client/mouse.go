@@ -0,0 +1,156 @@
+package client
+
+import (
+	"encoding/base64"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+const messageMenuPageName = "message-menu"
+
+// reactions are sent as messages quoting the reacted message, the protocol
+// has no reactions of its own.
+var reactions = []string{"👍", "❤", "😂", "😮"}
+
+// overlay is an open page over the dashboard, clicks outside of box close it.
+type overlay struct {
+	name string
+	box  tview.Primitive
+}
+
+// enableMouse turns the mouse on. Clicked panes become the current element of
+// the focus ring, so Tab continues from there.
+func (app *TerminalApp) enableMouse() {
+	app.app.EnableMouse(true)
+	app.app.SetMouseCapture(func(event *tcell.EventMouse, action tview.MouseAction) (*tcell.EventMouse, tview.MouseAction) {
+		if action == tview.MouseMove {
+			app.hoverMessages(event.Position())
+			return event, action
+		}
+		if action != tview.MouseLeftDown && action != tview.MouseRightDown {
+			return event, action
+		}
+		x, y := event.Position()
+		if app.overlay != nil {
+			if !inRect(app.overlay.box, x, y) {
+				app.closeOverlay()
+				return nil, action
+			}
+			return event, action
+		}
+		if front, _ := app.pages.GetFrontPage(); front != "dashboard" {
+			return event, action
+		}
+		name, ok := app.focusManager.find(func(box *tview.Box) bool {
+			return box.InRect(x, y)
+		})
+		if ok {
+			app.focusManager.focus(name)
+		}
+		return event, action
+	})
+}
+
+// hoverMessages shows the tooltip of the message under the pointer. Moves are
+// consumed by the panes under the pointer, so the views are told here.
+func (app *TerminalApp) hoverMessages(x, y int) {
+	if front, _ := app.pages.GetFrontPage(); front != "dashboard" {
+		x, y = -1, -1
+	}
+	changed := app.messageView.Hover(x, y)
+	if app.splitView.Hover(x, y) || changed {
+		// the event loop runs this, it can't wait for its own update
+		go app.app.Draw()
+	}
+}
+
+func inRect(p tview.Primitive, x, y int) bool {
+	left, top, width, height := p.GetRect()
+	return x >= left && x < left+width && y >= top && y < top+height
+}
+
+// showOverlay opens a page over the dashboard and focuses focus. Pages that
+// aren't resized keep the rect they were given.
+func (app *TerminalApp) showOverlay(name string, page, box, focus tview.Primitive, resize bool) {
+	app.closeOverlay()
+	app.overlay = &overlay{name: name, box: box}
+	app.pages.AddPage(name, page, resize, true)
+	app.app.SetFocus(focus)
+}
+
+// closeOverlay removes the open overlay and gives the focus back to the pane.
+func (app *TerminalApp) closeOverlay() {
+	if app.overlay == nil {
+		return
+	}
+	app.pages.RemovePage(app.overlay.name)
+	app.overlay = nil
+	app.restoreFocus()
+}
+
+// showMessageMenu opens the context menu of a message of the conversation with
+// peerId at the clicked position. The split view shows another conversation
+// than the selected one, so the menu acts on peerId.
+func (app *TerminalApp) showMessageMenu(peerId string, message DbMessage, x, y int) {
+	menu := tview.NewList().ShowSecondaryText(false)
+	menu.SetBorder(true).SetTitle(" Message ")
+	menu.AddItem("Reply", "", 'r', func() {
+		app.closeOverlay()
+		app.replyTo(peerId, message)
+	})
+	menu.AddItem("Copy", "", 'c', func() {
+		app.closeOverlay()
+		if err := copyToClipboard(message.text); err != nil {
+			slog.Warn("copy failed", "err", err)
+			app.printError("copy failed: " + tview.Escape(err.Error()))
+			return
+		}
+		app.printSystemLine("message copied")
+	})
+	for i, reaction := range reactions {
+		reaction := reaction
+		menu.AddItem("React "+reaction, "", rune('1'+i), func() {
+			app.closeOverlay()
+			app.sendTo(peerId, reaction+" > "+preview(message.text))
+		})
+	}
+	menu.SetDoneFunc(app.closeOverlay)
+	restyle(menu)
+
+	// next to the click, inside the screen
+	width, height := 16, menu.GetItemCount()+2
+	_, _, screenWidth, screenHeight := app.pages.GetRect()
+	menu.SetRect(max(0, min(x, screenWidth-width)), max(0, min(y, screenHeight-height)), width, height)
+
+	app.showOverlay(messageMenuPageName, menu, menu, menu, false)
+}
+
+// replyTo quotes a message in the composer.
+func (app *TerminalApp) replyTo(peerId string, message DbMessage) {
+	// the composer belongs to the selected conversation
+	if peerId != app.selectedUserId.Get() {
+		app.selectedUserId.Set(peerId)
+		app.conversationSelected(peerId)
+	}
+	var quote strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(message.text, "\n"), "\n") {
+		quote.WriteString("> " + line + "\n")
+	}
+	app.composer.SetText(quote.String()+app.composer.GetText(), true)
+	app.focusPane(paneComposer)
+}
+
+// copyToClipboard asks the terminal to set the clipboard with an OSC 52
+// sequence, which also works over ssh. Terminals may ignore it.
+func copyToClipboard(text string) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer tty.Close()
+	_, err = tty.WriteString("\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a")
+	return err
+}
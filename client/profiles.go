@@ -0,0 +1,56 @@
+package client
+
+import (
+	"chat/config"
+	"slices"
+)
+
+// Profiles are the servers offered by the login form: the configured ones
+// followed by those saved from the form into client.profiles_file.
+type Profiles struct {
+	configured []config.ServerProfile
+	saved      []config.ServerProfile
+	path       string
+}
+
+// NewProfiles loads the saved profiles, a missing file has none.
+func NewProfiles(cfg config.ClientConfig) (*Profiles, error) {
+	profiles := &Profiles{configured: cfg.ServerProfiles(), path: cfg.ProfilesFile}
+	if profiles.path == "" {
+		return profiles, nil
+	}
+	saved, err := config.LoadProfiles(profiles.path)
+	if err != nil {
+		return nil, err
+	}
+	profiles.saved = saved
+	return profiles, nil
+}
+
+// All lists the configured profiles, then the saved ones.
+func (p *Profiles) All() []config.ServerProfile {
+	return append(slices.Clip(p.configured), p.saved...)
+}
+
+// CanSave reports whether a profiles file is set.
+func (p *Profiles) CanSave() bool {
+	return p.path != ""
+}
+
+// Save replaces the saved profile of the same name or adds one, and writes
+// the file. It returns the index of the profile in All.
+func (p *Profiles) Save(profile config.ServerProfile) (int, error) {
+	saved := slices.Clone(p.saved)
+	index := slices.IndexFunc(saved, func(other config.ServerProfile) bool { return other.Name == profile.Name })
+	if index < 0 {
+		index = len(saved)
+		saved = append(saved, profile)
+	} else {
+		saved[index] = profile
+	}
+	if err := config.SaveProfiles(p.path, saved); err != nil {
+		return 0, err
+	}
+	p.saved = saved
+	return len(p.configured) + index, nil
+}
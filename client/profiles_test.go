@@ -0,0 +1,100 @@
+package client
+
+import (
+	"chat/config"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func profileNames(profiles []config.ServerProfile) []string {
+	names := make([]string, len(profiles))
+	for i, profile := range profiles {
+		names[i] = profile.Name
+	}
+	return names
+}
+
+func TestProfilesSave(t *testing.T) {
+	cfg := config.Default().Client
+	cfg.Profiles = []config.ServerProfile{{Name: "work", ServerAddress: "chat.example.com:8898"}}
+	cfg.ProfilesFile = filepath.Join(t.TempDir(), "chat", "profiles.yaml")
+	profiles, err := NewProfiles(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		profile config.ServerProfile
+		index   int
+		want    []string
+	}{
+		{config.ServerProfile{Name: "alice@a:1", ServerAddress: "a:1", Username: "alice"}, 2, []string{"default", "work", "alice@a:1"}},
+		{config.ServerProfile{Name: "bob@b:2", ServerAddress: "b:2", Username: "bob"}, 3, []string{"default", "work", "alice@a:1", "bob@b:2"}},
+		{config.ServerProfile{Name: "alice@a:1", ServerAddress: "a:1", Username: "alice", TLS: config.TLSConfig{Enabled: true}}, 2, []string{"default", "work", "alice@a:1", "bob@b:2"}},
+	}
+	for _, test := range tests {
+		index, err := profiles.Save(test.profile)
+		if err != nil {
+			t.Fatalf("save %s: %v", test.profile.Name, err)
+		}
+		if index != test.index {
+			t.Errorf("save %s: index = %d, want %d", test.profile.Name, index, test.index)
+		}
+		if got := profileNames(profiles.All()); !slices.Equal(got, test.want) {
+			t.Errorf("save %s: profiles = %v, want %v", test.profile.Name, got, test.want)
+		}
+	}
+
+	// the next start reads them back, the configured ones stay in the config
+	reloaded, err := NewProfiles(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := reloaded.All()
+	if got := profileNames(all); !slices.Equal(got, []string{"default", "work", "alice@a:1", "bob@b:2"}) {
+		t.Errorf("reloaded profiles = %v", got)
+	}
+	if !all[2].TLS.Enabled {
+		t.Error("updated profile lost its tls setting")
+	}
+}
+
+func TestProfilesLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"not yaml", "profiles: ["},
+		{"no address", "profiles:\n  - name: work\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := config.Default().Client
+			cfg.ProfilesFile = filepath.Join(dir, test.name+".yaml")
+			if err := os.WriteFile(cfg.ProfilesFile, []byte(test.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := NewProfiles(cfg); err == nil {
+				t.Error("profiles file accepted")
+			}
+		})
+	}
+}
+
+func TestProfilesWithoutFile(t *testing.T) {
+	cfg := config.Default().Client
+	cfg.ProfilesFile = ""
+	profiles, err := NewProfiles(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profiles.CanSave() {
+		t.Error("profiles without a file can be saved")
+	}
+	if got := profileNames(profiles.All()); !slices.Equal(got, []string{"default"}) {
+		t.Errorf("profiles = %v", got)
+	}
+}
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const searchPageName = "search"
+
+// searchContext is how many characters are kept before a match in the snippet.
+const searchContext = 20
+
+// compileSearchQuery builds a case-insensitive pattern, the query is a plain
+// substring unless regex is set.
+func compileSearchQuery(query string, regex bool) (*regexp.Regexp, error) {
+	if !regex {
+		query = regexp.QuoteMeta(query)
+	}
+	return regexp.Compile("(?i)" + query)
+}
+
+// matchSnippet shows the message around the first match, with the match
+// highlighted.
+func matchSnippet(text string, pattern *regexp.Regexp) string {
+	text = strings.ReplaceAll(text, "\n", " ")
+	location := pattern.FindStringIndex(text)
+	if location == nil {
+		return tview.Escape(text)
+	}
+	start, prefix := 0, ""
+	if location[0] > searchContext {
+		start, prefix = location[0]-searchContext, "…"
+		// do not cut a multi byte character
+		for start < location[0] && !utf8.RuneStart(text[start]) {
+			start++
+		}
+	}
+	return prefix + tview.Escape(text[start:location[0]]) +
+		activeTheme.highlightTag() + tview.Escape(text[location[0]:location[1]]) + "[-:-]" +
+		tview.Escape(text[location[1]:])
+}
+
+// showSearch opens the search overlay over the dashboard.
+func (app *TerminalApp) showSearch(query string) {
+	regex := false
+	var matches []MessageMatch
+
+	input := tview.NewInputField().SetLabel("Search: ").SetText(query)
+	results := tview.NewList().ShowSecondaryText(true)
+	status := tview.NewTextView().SetDynamicColors(true)
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, true).
+		AddItem(status, 1, 0, false).
+		AddItem(results, 0, 1, false)
+	layout.SetBorder(true).SetBorderPadding(0, 0, 1, 1)
+
+	run := func() {
+		results.Clear()
+		matches = nil
+		mode := "substring"
+		if regex {
+			mode = "regex"
+		}
+		hint := fmt.Sprintf("%s%s, Ctrl-R switches, Enter opens, Esc closes[-]", tag(activeTheme.Muted), mode)
+		layout.SetTitle(" Search messages (" + mode + ") ")
+
+		if input.GetText() == "" {
+			status.SetText(hint)
+			return
+		}
+		pattern, err := compileSearchQuery(input.GetText(), regex)
+		if err != nil {
+			status.SetText(tag(activeTheme.Error) + tview.Escape(err.Error()) + "[-]")
+			return
+		}
+		matches = app.data.SearchMessages(pattern)
+		status.SetText(fmt.Sprintf("%d matches, %s", len(matches), hint))
+		for _, match := range matches {
+			direction := "from"
+			if !match.message.incoming {
+				direction = "to"
+			}
+			description := fmt.Sprintf("%s%s %s, %s[-]", tag(activeTheme.Muted), direction, tview.Escape(match.username),
+				activeClock.Stamp(match.message.time.AsTime()))
+			results.AddItem(matchSnippet(match.message.text, pattern), description, 0, nil)
+		}
+	}
+
+	results.SetSelectedFunc(func(index int, _ string, _ string, _ rune) {
+		match := matches[index]
+		app.closeOverlay()
+		app.messageView.ShowMessage(match.peerId, match.index)
+		app.selectedUserId.Set(match.peerId)
+		app.focusPane(paneMessages)
+	})
+
+	input.SetChangedFunc(func(string) { run() })
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyCtrlR:
+			regex = !regex
+			run()
+			return nil
+		case tcell.KeyEnter, tcell.KeyDown, tcell.KeyTab:
+			if results.GetItemCount() > 0 {
+				app.app.SetFocus(results)
+			}
+			return nil
+		case tcell.KeyEscape:
+			app.closeOverlay()
+			return nil
+		}
+		return event
+	})
+	results.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyTab:
+			app.app.SetFocus(input)
+			return nil
+		case tcell.KeyUp:
+			if results.GetCurrentItem() == 0 {
+				app.app.SetFocus(input)
+				return nil
+			}
+		case tcell.KeyEscape:
+			app.closeOverlay()
+			return nil
+		}
+		return event
+	})
+
+	run()
+
+	// centered over the dashboard
+	overlay := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(layout, 0, 4, true).
+			AddItem(nil, 0, 1, false), 0, 4, true).
+		AddItem(nil, 0, 1, false)
+
+	restyle(layout)
+	app.showOverlay(searchPageName, overlay, layout, input, true)
+}
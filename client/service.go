@@ -1,65 +1,236 @@
 package client
 
 import (
+	"chat/config"
 	"chat/protos"
 	"context"
+	"errors"
 	"fmt"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
-	"log"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
 )
 
+// ErrNotRegistered is returned by calls that need a registered user.
+var ErrNotRegistered = errors.New("user is not registered")
+
+// RateLimitedError is returned when the server rejected a call for exceeding
+// its rate limit.
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("rate limit exceeded, retry after %s", e.RetryAfter)
+}
+
+// rateLimited converts a ResourceExhausted status and its retry-after trailer.
+func rateLimited(err error, trailer metadata.MD) error {
+	if status.Code(err) != codes.ResourceExhausted {
+		return err
+	}
+	retryAfter := time.Second
+	if values := trailer.Get("retry-after"); len(values) > 0 {
+		if seconds, parseErr := strconv.Atoi(values[0]); parseErr == nil {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+	}
+	return &RateLimitedError{RetryAfter: retryAfter}
+}
+
+// ConnectionState tells whether a session receives updates from the server.
+type ConnectionState int
+
+const (
+	// Disconnected is the state before registration and after logout.
+	Disconnected ConnectionState = iota
+	Connected
+	// ConnectionLost means the update stream ended without a logout.
+	ConnectionLost
+)
+
+func (c ConnectionState) String() string {
+	switch c {
+	case Connected:
+		return "connected"
+	case ConnectionLost:
+		return "connection lost"
+	}
+	return "disconnected"
+}
+
 type ChatService interface {
-	GetUserId() (id string)
-	GetUsername() (username string)
+	CheckHealth() error
+	GetUserId() (id string, err error)
+	GetUsername() (username string, err error)
 	GetUserDetails(clientId string) string
 	AllUsers() []User
-	Register(username string) error
-	SendMessage(receiverId, message string) DbMessage
-	ReadMessages(clientId string) []DbMessage
+	Connect(address string, tls config.TLSConfig) error
+	Register(username, password string) error
+	Logout() error
+	SendMessage(receiverId, message string) (DbMessage, error)
+	ReadMessagesPage(clientId string, before, limit int) ([]DbMessage, int)
 	SendNotification(clientId string)
+	ToggleFavourite(clientId string) bool
 	NewMessageNotification() <-chan protos.DirectMessage
 	OnlineUserChangedNotification() <-chan bool
+	AnnouncementNotification() <-chan protos.Announcement
+	Connection() Signal[ConnectionState]
+	UnreadCounts() Signal[map[string]int]
+	TotalUnread() Signal[int]
 	CanChatWith(clientId string) bool
+	BlockUser(clientId string) error
+	UnblockUser(clientId string) error
+	BlockedUsers() []User
+	SetStatus(status string) error
+	ChangeNickname(username, displayName string) error
+	GetDisplayName() string
+	ConversationDetails(clientId string) ConversationDetails
+	SearchMessages(pattern *regexp.Regexp) []MessageMatch
+	SaveDraft(clientId, text string)
+	Draft(clientId string) string
+	SentMessages(clientId string) []string
 }
 
 type ChatServiceImplementation struct {
 	database LocalDatabase
-
-	user               *protos.User
+	config   config.ClientConfig
+
+	user *protos.User
+	// conn goes to address, Connect replaces it while logged out. connMu
+	// guards conn and healthClient, CheckHealth runs off the ui goroutine.
+	connMu             sync.Mutex
+	conn               *grpc.ClientConn
+	address            string
+	tls                config.TLSConfig
 	registerUserClient protos.RegisterUserClient
+	healthClient       healthpb.HealthClient
 
 	newMessages       chan protos.DirectMessage
 	userStatusUpdated chan bool
+	announcements     chan protos.Announcement
+	// stopUpdates cancels the update stream, updatesDone is closed when its
+	// goroutine has returned
+	stopUpdates context.CancelFunc
+	updatesDone chan struct{}
+
+	connection SignalState[ConnectionState]
+	// unread maps client ids to their unread counts, a new map is set on
+	// every change so readers may keep it
+	unread      SignalState[map[string]int]
+	totalUnread Signal[int]
 
 	appStopRequest chan<- bool
 }
 
-func NewChatServiceImplementation(appEndRequest chan<- bool, database LocalDatabase) *ChatServiceImplementation {
-	return &ChatServiceImplementation{appStopRequest: appEndRequest, database: database}
+func NewChatServiceImplementation(appEndRequest chan<- bool, database LocalDatabase, cfg config.ClientConfig) *ChatServiceImplementation {
+	unread := NewSignal(map[string]int{})
+	// derived for the lifetime of the service, it is never detached
+	totalUnread, _ := DeriveSignal[map[string]int](unread, func(counts map[string]int) int {
+		total := 0
+		for _, count := range counts {
+			total += count
+		}
+		return total
+	})
+	return &ChatServiceImplementation{
+		appStopRequest: appEndRequest,
+		database:       database,
+		config:         cfg,
+		connection:     NewSignal(Disconnected),
+		unread:         unread,
+		totalUnread:    totalUnread,
+	}
 }
 
-func (s *ChatServiceImplementation) InitGrpcClient(conn *grpc.ClientConn) {
+// Connect sets the server of the next registration. Dialing doesn't wait for
+// the server, CheckHealth does. Connecting to the current server keeps its
+// connection.
+func (s *ChatServiceImplementation) Connect(address string, tls config.TLSConfig) error {
+	if s.user != nil {
+		return errors.New("log out before connecting to another server")
+	}
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	if s.conn != nil && address == s.address && tls == s.tls {
+		return nil
+	}
+	transport, err := config.ClientCredentials(tls)
+	if err != nil {
+		return err
+	}
+	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(transport),
+		grpc.WithUnaryInterceptor(s.UnaryClientInterceptor),
+		grpc.WithStreamInterceptor(s.StreamClientInterceptor),
+	)
+	if err != nil {
+		return fmt.Errorf("did not connect: %w", err)
+	}
+	if s.conn != nil {
+		// a check still running on it fails
+		s.conn.Close()
+	}
+	s.conn, s.address, s.tls = conn, address, tls
 	s.registerUserClient = protos.NewRegisterUserClient(conn)
+	s.healthClient = healthpb.NewHealthClient(conn)
+	slog.Info("server selected", "address", address, "tls", tls.Enabled)
+	return nil
+}
+
+// Close closes the connection to the server.
+func (s *ChatServiceImplementation) Close() error {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.Close()
 }
 
-func (s *ChatServiceImplementation) GetUserId() (id string) {
+// CheckHealth asks the server whether the chat service is serving. The probe
+// is bounded by health_timeout, so an unreachable server is reported quickly.
+func (s *ChatServiceImplementation) CheckHealth() error {
+	s.connMu.Lock()
+	healthClient := s.healthClient
+	s.connMu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.HealthTimeout)
+	defer cancel()
+
+	response, err := healthClient.Check(ctx, &healthpb.HealthCheckRequest{Service: "RegisterUser"}, grpc.WaitForReady(true))
+	if err != nil {
+		return fmt.Errorf("server unavailable: %w", err)
+	}
+	if response.Status != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("server unavailable: chat service is %s", response.Status)
+	}
+	return nil
+}
+
+func (s *ChatServiceImplementation) GetUserId() (id string, err error) {
 	if s.user == nil {
-		log.Fatalln("user object not found")
+		return "", ErrNotRegistered
 	}
-	return s.user.Id
+	return s.user.Id, nil
 }
 
+// GetUserDetails returns the label of a user, its display name if it has one.
 func (s *ChatServiceImplementation) GetUserDetails(clientId string) string {
 	user := s.database.GetUser(clientId)
-	return user.username
+	return user.label()
 }
 
-func (s *ChatServiceImplementation) GetUsername() (username string) {
+func (s *ChatServiceImplementation) GetUsername() (username string, err error) {
 	if s.user == nil {
-		log.Fatalln("user object not found")
+		return "", ErrNotRegistered
 	}
-	return s.user.Username
+	return s.user.Username, nil
 }
 
 func (s *ChatServiceImplementation) NewMessageNotification() <-chan protos.DirectMessage {
@@ -69,26 +240,71 @@ func (s *ChatServiceImplementation) OnlineUserChangedNotification() <-chan bool
 	return s.userStatusUpdated
 }
 
+func (s *ChatServiceImplementation) AnnouncementNotification() <-chan protos.Announcement {
+	return s.announcements
+}
+
+// Connection follows the update stream of the session.
+func (s *ChatServiceImplementation) Connection() Signal[ConnectionState] {
+	return s.connection
+}
+
+// UnreadCounts maps the client ids with unread messages to their counts.
+func (s *ChatServiceImplementation) UnreadCounts() Signal[map[string]int] {
+	return s.unread
+}
+
+func (s *ChatServiceImplementation) TotalUnread() Signal[int] {
+	return s.totalUnread
+}
+
+// publishUnread sets the unread signal after the counts changed in the db.
+func (s *ChatServiceImplementation) publishUnread() {
+	counts := make(map[string]int)
+	for _, user := range s.database.ListAllUsers() {
+		if user.unread > 0 {
+			counts[user.id] = user.unread
+		}
+	}
+	s.unread.Set(counts)
+}
+
 func (s *ChatServiceImplementation) CanChatWith(clientId string) bool {
 	return s.database.UserOnline(clientId)
 }
 
 func (s *ChatServiceImplementation) UnaryClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-	log.Println("UNARY INTERCEPTOR", "method:", method)
+	logger := slog.With("method", method)
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline && s.config.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.config.RequestTimeout)
+		defer cancel()
+	}
 	userRegistered := s.user != nil
 	if userRegistered {
 		ctx = metadata.AppendToOutgoingContext(ctx, "client-id", s.user.Id)
+		logger = logger.With("client_id", s.user.Id)
 	}
 	err := invoker(ctx, method, req, reply, cc, opts...)
-	return err
+	if err != nil {
+		logger.Warn("unary call failed", "err", err)
+		return err
+	}
+	logger.Debug("unary call finished")
+	return nil
 }
 
 func (s *ChatServiceImplementation) StreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
-	log.Println("STREAM INTERCEPTOR", "method:", method)
+	if s.user == nil {
+		return nil, ErrNotRegistered
+	}
+	logger := slog.With("method", method, "client_id", s.user.Id)
 	stream, err := streamer(metadata.AppendToOutgoingContext(ctx, "client-id", s.user.Id), desc, cc, method, opts...)
 	if err != nil {
+		logger.Warn("stream call failed", "err", err)
 		return nil, err
 	}
+	logger.Debug("stream opened")
 	return stream, nil
 }
 
@@ -96,83 +312,158 @@ func (s *ChatServiceImplementation) AllUsers() []User {
 	return s.database.ListAllUsers()
 }
 
-func (s *ChatServiceImplementation) Register(username string) error {
+func (s *ChatServiceImplementation) Register(username, password string) (err error) {
 	newUser, err := s.registerUserClient.Register(context.Background(), &protos.RegisterRequest{
 		Username: username,
+		Password: password,
 	})
 
 	if err != nil {
-		log.Printf("registration failed: %s\n", err.Error())
+		slog.Warn("registration failed", "username", username, "err", err)
 		return err
 	}
 
 	s.user = newUser
-	s.subscribe()
-	log.Printf("user online, id: %s\n", newUser.Id)
+	defer func() {
+		// a half started session is ended, so that Connect and another
+		// Register work and the server drops the user
+		if err != nil {
+			s.endSession()
+		}
+	}()
+	// drafts and pins belong to the login username at this server
+	s.database.SetAccount(username + "@" + s.address)
+	if err := s.subscribe(); err != nil {
+		return err
+	}
+	s.connection.Set(Connected)
+	slog.Info("user online", "client_id", newUser.Id, "username", newUser.Username)
 
 	// register all users to the db
 	list, err := s.registerUserClient.List(context.Background(), &protos.Empty{})
+	if err != nil {
+		return fmt.Errorf("load user list: %w", err)
+	}
 	for _, u := range list.Users {
-		s.database.AddUser(&protos.User{
-			Id:       u.Id,
-			Username: u.Username,
-		})
+		s.database.AddUser(u)
+	}
+
+	// blocks of accounts are kept by the server
+	blocked, err := s.registerUserClient.ListBlocked(context.Background(), &protos.Empty{})
+	if err != nil {
+		return fmt.Errorf("load blocked users: %w", err)
+	}
+	for _, u := range blocked.Users {
+		s.database.AddBlockedUser(User{id: u.Id, username: u.Username, displayName: u.DisplayName})
 	}
 	return nil
 }
 
-func (s *ChatServiceImplementation) subscribe() {
+func (s *ChatServiceImplementation) subscribe() error {
 	if s.newMessages != nil && s.userStatusUpdated != nil {
-		log.Fatal("grpc stream already active")
+		return errors.New("grpc stream already active")
 	}
-	log.Println("Loading update channels...")
-
-	s.newMessages = make(chan protos.DirectMessage, 100)
-	s.userStatusUpdated = make(chan bool, 100)
+	slog.Debug("loading update channels")
 
-	stream, err := s.registerUserClient.GetUpdates(context.Background(), &protos.SubscriptionRequest{})
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := s.registerUserClient.GetUpdates(ctx, &protos.SubscriptionRequest{})
 	if err != nil {
-		log.Fatal("subscription request failed:", err.Error())
+		cancel()
+		return fmt.Errorf("subscription request failed: %w", err)
 	}
 
+	// the goroutine closes the channels of its session, Logout replaces them
+	newMessages := make(chan protos.DirectMessage, s.config.QueueSize)
+	userStatusUpdated := make(chan bool, s.config.QueueSize)
+	announcements := make(chan protos.Announcement, s.config.QueueSize)
+	done := make(chan struct{})
+	s.newMessages, s.userStatusUpdated, s.announcements = newMessages, userStatusUpdated, announcements
+	s.stopUpdates, s.updatesDone = cancel, done
+
 	go func() {
+		defer func() {
+			close(newMessages)
+			close(userStatusUpdated)
+			close(announcements)
+			close(done)
+		}()
 		for {
 			update, err := stream.Recv()
+			if ctx.Err() != nil {
+				slog.Debug("update stream closed")
+				return
+			}
 			if err != nil {
-				log.Println("connection lost")
-				s.appStopRequest <- true
+				slog.Error("connection lost", "err", err)
+				s.connection.Set(ConnectionLost)
+				// a lost connection of an earlier session may still fill
+				// the request, the goroutine must return for Logout
+				select {
+				case s.appStopRequest <- true:
+				default:
+				}
 				return
 			}
 
 			switch updateContent := update.Content.(type) {
 			case *protos.ServerUpdate_IncomingMessage:
 				im := updateContent.IncomingMessage
-				s.database.SaveIncomingMessage(*im)
-				s.newMessages <- *im
-				log.Println("new message!")
+				if _, err := s.database.SaveIncomingMessage(*im); err != nil {
+					slog.Warn("incoming message dropped", "sender_id", im.SenderId, "err", err)
+					continue
+				}
+				if !deliver(ctx, newMessages, *im) {
+					return
+				}
+				slog.Debug("new message", "sender_id", im.SenderId)
 			case *protos.ServerUpdate_UserOnlineStatus:
 				listUserChange := updateContent.UserOnlineStatus
 				if listUserChange.Add {
-					s.database.AddUser(&protos.User{
-						Id:       listUserChange.Changed.Id,
-						Username: listUserChange.Changed.Username,
-					})
+					s.database.AddUser(listUserChange.Changed)
 				} else {
 					s.database.DeleteUser(listUserChange.Changed.Id)
+					s.publishUnread()
+				}
+				if !deliver(ctx, userStatusUpdated, true) {
+					return
 				}
-				s.userStatusUpdated <- true
+			case *protos.ServerUpdate_ProfileChanged:
+				changed := updateContent.ProfileChanged.Changed
+				s.database.AddUser(changed)
+				slog.Info("profile changed", "client_id", changed.Id, "previous_username", updateContent.ProfileChanged.PreviousUsername, "username", changed.Username)
+				// the labels are redrawn with the list
+				if !deliver(ctx, userStatusUpdated, true) {
+					return
+				}
+			case *protos.ServerUpdate_Announcement:
+				if !deliver(ctx, announcements, *updateContent.Announcement) {
+					return
+				}
+				slog.Info("server announcement", "text", updateContent.Announcement.Text)
 			default:
-				log.Printf("Received unknown update type")
+				slog.Warn("received unknown update type")
 			}
 		}
 	}()
 
+	return nil
+}
+
+// deliver queues an update unless the stream was closed meanwhile, the ui may
+// have stopped reading by then.
+func deliver[T any](ctx context.Context, channel chan<- T, update T) bool {
+	select {
+	case channel <- update:
+		return true
+	case <-ctx.Done():
+		return false
+	}
 }
 
 func (s *ChatServiceImplementation) getAllUsers() *protos.UserList {
 	allUsers, err := s.registerUserClient.List(context.Background(), &protos.Empty{})
 	if err != nil {
-		log.Println("users loading failed:", err.Error())
+		slog.Warn("users loading failed", "err", err)
 		return &protos.UserList{
 			Users: make([]*protos.User, 0),
 		}
@@ -180,36 +471,180 @@ func (s *ChatServiceImplementation) getAllUsers() *protos.UserList {
 	return allUsers
 }
 
-func (s *ChatServiceImplementation) SendMessage(receiverId, message string) DbMessage {
+func (s *ChatServiceImplementation) SendMessage(receiverId, message string) (DbMessage, error) {
 	dm := &protos.NewMessage{
 		ReceiverId: receiverId,
 		Message:    message,
 	}
-	mess, err := s.registerUserClient.SendDirectMessage(context.Background(), dm)
+	var trailer metadata.MD
+	mess, err := s.registerUserClient.SendDirectMessage(context.Background(), dm, grpc.Trailer(&trailer))
 	if err != nil {
-		fmt.Println("message:", err.Error())
+		return DbMessage{}, rateLimited(err, trailer)
 	}
 
-	s.database.SaveOutgoingMessage(receiverId, message)
+	if err := s.database.SaveOutgoingMessage(receiverId, message); err != nil {
+		return DbMessage{}, err
+	}
 	return DbMessage{
 		incoming: false,
 		text:     mess.Message,
 		time:     mess.Time,
-	}
+	}, nil
 }
 
-func (s *ChatServiceImplementation) ReadMessages(clientId string) []DbMessage {
-	s.database.RemoveNotification(clientId)
-	return s.database.GetMessages(clientId)
+// ReadMessagesPage loads a page of history, reading the newest page also
+// clears the new message notification.
+func (s *ChatServiceImplementation) ReadMessagesPage(clientId string, before, limit int) ([]DbMessage, int) {
+	if before < 0 {
+		s.database.RemoveNotification(clientId)
+		s.publishUnread()
+	}
+	return s.database.GetMessagesPage(clientId, before, limit)
 }
 
 func (s *ChatServiceImplementation) SendNotification(clientId string) {
 	s.database.AddNewMessageNotification(clientId)
+	s.publishUnread()
 }
 
-func (s *ChatServiceImplementation) Unregister() {
+// ToggleFavourite pins or unpins a user at the top of the list and reports
+// whether it is pinned now.
+func (s *ChatServiceImplementation) ToggleFavourite(clientId string) bool {
+	user := s.database.GetUser(clientId)
+	if user.id == "" {
+		return false
+	}
+	s.database.SetFavourite(user.username, !user.favourite)
+	return !user.favourite
+}
+
+func (s *ChatServiceImplementation) Unregister() error {
+	if s.user == nil {
+		return nil
+	}
 	_, err := s.registerUserClient.Deregister(context.Background(), &protos.Empty{})
 	if err != nil {
-		log.Fatalln("registration failed:", err)
+		return fmt.Errorf("deregistration failed: %w", err)
+	}
+	return nil
+}
+
+// Logout ends the session so another user can register: the update stream is
+// closed before deregistering, the server closing it would look like a lost
+// connection. Users and conversations of the session are dropped.
+func (s *ChatServiceImplementation) Logout() error {
+	if s.user == nil {
+		return ErrNotRegistered
+	}
+	return s.endSession()
+}
+
+// endSession stops the update stream if it was started, deregisters and
+// forgets the user.
+func (s *ChatServiceImplementation) endSession() error {
+	if s.stopUpdates != nil {
+		s.stopUpdates()
+		<-s.updatesDone
+	}
+	err := s.Unregister()
+	slog.Info("user offline", "client_id", s.user.Id, "username", s.user.Username)
+
+	s.user = nil
+	s.newMessages, s.userStatusUpdated, s.announcements = nil, nil, nil
+	s.stopUpdates, s.updatesDone = nil, nil
+	s.database.Clear()
+	s.connection.Set(Disconnected)
+	s.publishUnread()
+	return err
+}
+
+// BlockUser stops messages from the user, the server also hides it from the list.
+func (s *ChatServiceImplementation) BlockUser(clientId string) error {
+	user := s.database.GetUser(clientId)
+	if user.id == "" {
+		return ErrUnknownUser
+	}
+	_, err := s.registerUserClient.BlockUser(context.Background(), &protos.BlockRequest{UserId: clientId})
+	if err != nil {
+		return err
 	}
+	s.database.AddBlockedUser(user)
+	return nil
+}
+
+func (s *ChatServiceImplementation) UnblockUser(clientId string) error {
+	_, err := s.registerUserClient.UnblockUser(context.Background(), &protos.BlockRequest{UserId: clientId})
+	// not blocked on the server, only the local entry is stale
+	if err != nil && status.Code(err) != codes.NotFound {
+		return err
+	}
+	s.database.RemoveBlockedUser(clientId)
+	return nil
+}
+
+func (s *ChatServiceImplementation) BlockedUsers() []User {
+	return s.database.ListBlockedUsers()
+}
+
+func (s *ChatServiceImplementation) SetStatus(status string) error {
+	updated, err := s.registerUserClient.SetStatus(context.Background(), &protos.StatusRequest{Status: status})
+	if err != nil {
+		return err
+	}
+	s.user = updated
+	s.database.AddUser(updated)
+	return nil
+}
+
+// ChangeNickname renames the user and sets its display name, an empty
+// display name clears it.
+func (s *ChatServiceImplementation) ChangeNickname(username, displayName string) error {
+	updated, err := s.registerUserClient.ChangeNickname(context.Background(), &protos.NicknameRequest{
+		Username:    username,
+		DisplayName: displayName,
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("nickname changed", "previous_username", s.user.Username, "username", updated.Username)
+	s.user = updated
+	s.database.AddUser(updated)
+	return nil
+}
+
+func (s *ChatServiceImplementation) GetDisplayName() string {
+	if s.user == nil {
+		return ""
+	}
+	return s.user.DisplayName
+}
+
+func (s *ChatServiceImplementation) ConversationDetails(clientId string) ConversationDetails {
+	details := ConversationDetails{User: s.database.GetUser(clientId)}
+	for _, message := range s.database.GetMessages(clientId) {
+		if message.incoming {
+			details.Incoming++
+		} else {
+			details.Outgoing++
+		}
+		details.LastMessage = message.time.AsTime()
+		details.Links = append(details.Links, linkPattern.FindAllString(message.text, -1)...)
+	}
+	return details
+}
+
+func (s *ChatServiceImplementation) SearchMessages(pattern *regexp.Regexp) []MessageMatch {
+	return s.database.SearchMessages(pattern)
+}
+
+func (s *ChatServiceImplementation) SaveDraft(clientId, text string) {
+	s.database.SaveDraft(clientId, text)
+}
+
+func (s *ChatServiceImplementation) Draft(clientId string) string {
+	return s.database.Draft(clientId)
+}
+
+func (s *ChatServiceImplementation) SentMessages(clientId string) []string {
+	return s.database.SentMessages(clientId)
 }
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"chat/config"
+	"chat/protos"
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// fakeRegisterClient answers the calls of a registration, the other calls
+// of the interface are not implemented.
+type fakeRegisterClient struct {
+	protos.RegisterUserClient
+	listErr      error
+	deregistered int
+}
+
+func (c *fakeRegisterClient) Register(_ context.Context, request *protos.RegisterRequest, _ ...grpc.CallOption) (*protos.User, error) {
+	return &protos.User{Id: "1", Username: request.Username}, nil
+}
+
+func (c *fakeRegisterClient) GetUpdates(ctx context.Context, _ *protos.SubscriptionRequest, _ ...grpc.CallOption) (protos.RegisterUser_GetUpdatesClient, error) {
+	return &fakeUpdates{ctx: ctx}, nil
+}
+
+func (c *fakeRegisterClient) List(context.Context, *protos.Empty, ...grpc.CallOption) (*protos.UserList, error) {
+	if c.listErr != nil {
+		return nil, c.listErr
+	}
+	return &protos.UserList{}, nil
+}
+
+func (c *fakeRegisterClient) ListBlocked(context.Context, *protos.Empty, ...grpc.CallOption) (*protos.UserList, error) {
+	return &protos.UserList{}, nil
+}
+
+func (c *fakeRegisterClient) Deregister(context.Context, *protos.Empty, ...grpc.CallOption) (*protos.Empty, error) {
+	c.deregistered++
+	return &protos.Empty{}, nil
+}
+
+// fakeUpdates is an update stream without updates, it ends when cancelled.
+type fakeUpdates struct {
+	grpc.ClientStream
+	ctx context.Context
+}
+
+func (u *fakeUpdates) Recv() (*protos.ServerUpdate, error) {
+	<-u.ctx.Done()
+	return nil, u.ctx.Err()
+}
+
+func TestRegisterFailureEndsTheSession(t *testing.T) {
+	server := &fakeRegisterClient{listErr: errors.New("unavailable")}
+	service := NewChatServiceImplementation(make(chan bool, 1), NewInMemoryChatDatabase(), config.Default().Client)
+	service.registerUserClient = server
+
+	if err := service.Register("alice", ""); err == nil {
+		t.Fatal("registration with a failing list succeeded")
+	}
+	if server.deregistered != 1 {
+		t.Errorf("deregistered %d times, want 1", server.deregistered)
+	}
+	if _, err := service.GetUserId(); !errors.Is(err, ErrNotRegistered) {
+		t.Errorf("user kept after the failure: %v", err)
+	}
+	if err := service.Logout(); !errors.Is(err, ErrNotRegistered) {
+		t.Errorf("Logout = %v, want ErrNotRegistered", err)
+	}
+
+	server.listErr = nil
+	if err := service.Register("alice", ""); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if err := service.Logout(); err != nil {
+		t.Errorf("Logout: %v", err)
+	}
+}
+
+func TestConnectDuringHealthCheck(t *testing.T) {
+	cfg := config.Default().Client
+	cfg.HealthTimeout = time.Millisecond
+	service := NewChatServiceImplementation(make(chan bool, 1), NewInMemoryChatDatabase(), cfg)
+	defer service.Close()
+	// the checks must run in parallel with Connect to race
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(max(4, runtime.GOMAXPROCS(0))))
+
+	if err := service.Connect("127.0.0.1:1", config.TLSConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for n := 0; n < 200; n++ {
+			// nothing listens, only the race matters
+			service.CheckHealth()
+		}
+	}()
+	for n := 0; n < 200; n++ {
+		if err := service.Connect(fmt.Sprintf("127.0.0.1:%d", 1+n%2), config.TLSConfig{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+}
@@ -1,37 +1,157 @@
 package client
 
-import "log"
+import "sync"
 
+// Signal is a value that can be watched from any goroutine. Subscribers get
+// the current value first and then the changes; a subscriber that falls
+// behind skips to the latest value instead of blocking the writer.
+type Signal[T any] interface {
+	Get() T
+	// Subscribe returns the channel of values and a function that ends the
+	// subscription, it closes the channel.
+	Subscribe() (<-chan T, func())
+	signalSource
+}
+
+// SignalState is a signal that is set directly, computed signals are not.
 type SignalState[T any] interface {
-	getUpdateChannel() chan T
-	pushValue(T)
-	getCurrentValue() T
+	Signal[T]
+	Set(T)
+}
+
+// signalSource tells computed signals about changes, whatever the value type.
+type signalSource interface {
+	// watch calls changed after every set until the returned function is
+	// called
+	watch(changed func()) func()
 }
 
 type signalImplementation[T any] struct {
-	value        T
-	observerList []chan T
+	// setting orders the sets, so watchers see the values in the same order
+	// as subscribers
+	setting sync.Mutex
+
+	mutex sync.RWMutex
+	value T
+	// subscriber channels are only written and closed under mutex
+	subscribers map[int]chan T
+	watchers    map[int]func()
+	nextId      int
+}
+
+// NewSignal creates a signal with a first value.
+func NewSignal[T any](value T) SignalState[T] {
+	return newSignal(value)
+}
+
+func newSignal[T any](value T) *signalImplementation[T] {
+	return &signalImplementation[T]{value: value, subscribers: make(map[int]chan T), watchers: make(map[int]func())}
+}
+
+// ComputeSignal keeps the result of compute, which is called again whenever
+// one of the sources is set; compute must not set them. The sources keep the
+// signal until the returned function detaches it, the signal keeps its last
+// value afterwards.
+func ComputeSignal[T any](compute func() T, sources ...signalSource) (Signal[T], func()) {
+	computed := newSignal(compute())
+	cancels := make([]func(), 0, len(sources))
+	for _, source := range sources {
+		cancels = append(cancels, source.watch(func() {
+			// computed under the lock, a slower computation of an older
+			// source value can't overwrite a newer one
+			computed.setting.Lock()
+			defer computed.setting.Unlock()
+			computed.set(compute())
+		}))
+	}
+	return computed, func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}
 }
 
-func (s *signalImplementation[T]) getUpdateChannel() chan T {
-	newSubscriberChannel := make(chan T, 1)
-	newSubscriberChannel <- s.getCurrentValue()
-	s.observerList = append(s.observerList, newSubscriberChannel)
-	return newSubscriberChannel
+// DeriveSignal follows a source through derive, see ComputeSignal.
+func DeriveSignal[T, U any](source Signal[T], derive func(T) U) (Signal[U], func()) {
+	return ComputeSignal(func() U { return derive(source.Get()) }, source)
 }
 
-func (s *signalImplementation[T]) getCurrentValue() T {
+func (s *signalImplementation[T]) Get() T {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 	return s.value
 }
 
-func (s *signalImplementation[T]) pushValue(value T) {
+func (s *signalImplementation[T]) Set(value T) {
+	s.setting.Lock()
+	defer s.setting.Unlock()
+	s.set(value)
+}
+
+// set stores the value and notifies, the caller holds setting. Watchers run
+// without mutex, they read their sources.
+func (s *signalImplementation[T]) set(value T) {
+	s.mutex.Lock()
 	s.value = value
-	for _, channel := range s.observerList {
-		select {
-		case channel <- value:
-			log.Printf("value <%v> send to channel <%v>\n", value, channel)
-		default:
-			log.Fatalf("value <%v> has not beed sent. channel blocked <%v>\n", value, channel)
-		}
+	for _, channel := range s.subscribers {
+		offer(channel, value)
+	}
+	watchers := make([]func(), 0, len(s.watchers))
+	for _, changed := range s.watchers {
+		watchers = append(watchers, changed)
+	}
+	s.mutex.Unlock()
+
+	for _, changed := range watchers {
+		changed()
+	}
+}
+
+// offer replaces a value the subscriber hasn't read yet, only the latest one
+// matters. There is room for the last send, the subscriber only takes values
+// out and nobody else writes.
+func offer[T any](channel chan T, value T) {
+	select {
+	case channel <- value:
+		return
+	default:
+	}
+	select {
+	case <-channel:
+	default:
+	}
+	channel <- value
+}
+
+func (s *signalImplementation[T]) Subscribe() (<-chan T, func()) {
+	channel := make(chan T, 1)
+	s.mutex.Lock()
+	channel <- s.value
+	id := s.nextId
+	s.nextId++
+	s.subscribers[id] = channel
+	s.mutex.Unlock()
+
+	var once sync.Once
+	return channel, func() {
+		once.Do(func() {
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+			delete(s.subscribers, id)
+			close(channel)
+		})
+	}
+}
+
+func (s *signalImplementation[T]) watch(changed func()) func() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	id := s.nextId
+	s.nextId++
+	s.watchers[id] = changed
+	return func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		delete(s.watchers, id)
 	}
 }
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// receive waits for a value, signals deliver before Set returns so a short
+// timeout is enough.
+func receive[T any](t *testing.T, channel <-chan T) (T, bool) {
+	t.Helper()
+	select {
+	case value, ok := <-channel:
+		return value, ok
+	case <-time.After(time.Second):
+		t.Fatal("no value received")
+	}
+	var zero T
+	return zero, false
+}
+
+func assertEmpty[T any](t *testing.T, channel <-chan T) {
+	t.Helper()
+	select {
+	case value := <-channel:
+		t.Fatalf("unexpected value %v", value)
+	default:
+	}
+}
+
+func TestSignalSubscribe(t *testing.T) {
+	signal := NewSignal("a")
+	values, unsubscribe := signal.Subscribe()
+	defer unsubscribe()
+
+	if value, _ := receive(t, values); value != "a" {
+		t.Errorf("first value = %q, want the current value a", value)
+	}
+	signal.Set("b")
+	if value, _ := receive(t, values); value != "b" {
+		t.Errorf("value = %q, want b", value)
+	}
+	if got := signal.Get(); got != "b" {
+		t.Errorf("Get = %q, want b", got)
+	}
+	assertEmpty(t, values)
+}
+
+func TestSignalUnsubscribe(t *testing.T) {
+	signal := NewSignal(0)
+	values, unsubscribe := signal.Subscribe()
+	other, unsubscribeOther := signal.Subscribe()
+	defer unsubscribeOther()
+	receive(t, values)
+	receive(t, other)
+
+	unsubscribe()
+	if _, ok := receive(t, values); ok {
+		t.Error("channel not closed by unsubscribe")
+	}
+	// a second call is harmless, sets don't reach the closed channel
+	unsubscribe()
+	signal.Set(1)
+	if value, _ := receive(t, other); value != 1 {
+		t.Errorf("other subscriber got %d, want 1", value)
+	}
+}
+
+func TestSignalCoalescesSlowSubscribers(t *testing.T) {
+	signal := NewSignal(0)
+	values, unsubscribe := signal.Subscribe()
+	defer unsubscribe()
+
+	// nobody reads, Set must not block
+	for i := 1; i <= 1000; i++ {
+		signal.Set(i)
+	}
+	if value, _ := receive(t, values); value != 1000 {
+		t.Errorf("value = %d, want the latest 1000", value)
+	}
+	assertEmpty(t, values)
+}
+
+func TestDeriveSignal(t *testing.T) {
+	source := NewSignal(2)
+	derived, detach := DeriveSignal[int](source, func(value int) int { return value * 10 })
+	if got := derived.Get(); got != 20 {
+		t.Errorf("initial = %d, want 20", got)
+	}
+	values, unsubscribe := derived.Subscribe()
+	defer unsubscribe()
+	receive(t, values)
+
+	source.Set(3)
+	if got := derived.Get(); got != 30 {
+		t.Errorf("Get after Set = %d, want 30", got)
+	}
+	if value, _ := receive(t, values); value != 30 {
+		t.Errorf("value = %d, want 30", value)
+	}
+
+	detach()
+	source.Set(4)
+	if got := derived.Get(); got != 30 {
+		t.Errorf("detached signal changed to %d", got)
+	}
+	assertEmpty(t, values)
+}
+
+func TestComputeSignal(t *testing.T) {
+	first := NewSignal(1)
+	second := NewSignal("x")
+	computed, detach := ComputeSignal(func() string {
+		return second.Get() + string(rune('0'+first.Get()))
+	}, first, second)
+	defer detach()
+
+	first.Set(2)
+	second.Set("y")
+	if got := computed.Get(); got != "y2" {
+		t.Errorf("computed = %q, want y2", got)
+	}
+}
+
+func TestSignalConcurrentUse(t *testing.T) {
+	source := NewSignal(0)
+	derived, detach := DeriveSignal[int](source, func(value int) int { return -value })
+	defer detach()
+
+	const writers, sets = 8, 500
+	var readers sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			values, unsubscribe := derived.Subscribe()
+			defer unsubscribe()
+			for {
+				select {
+				case <-stop:
+					return
+				case <-values:
+				}
+			}
+		}()
+	}
+	// short lived subscriptions race with the sets
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		for i := 0; i < sets; i++ {
+			_, unsubscribe := source.Subscribe()
+			unsubscribe()
+		}
+	}()
+
+	var setters sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		setters.Add(1)
+		go func(w int) {
+			defer setters.Done()
+			for i := 0; i < sets; i++ {
+				source.Set(w*sets + i)
+				source.Get()
+			}
+		}(w)
+	}
+	setters.Wait()
+	close(stop)
+	readers.Wait()
+
+	if got, want := derived.Get(), -source.Get(); got != want {
+		t.Errorf("derived = %d, want %d from the last set", got, want)
+	}
+}
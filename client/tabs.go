@@ -0,0 +1,196 @@
+package client
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// tabRegionPrefix names the tab bar regions, clicking a region switches tabs.
+const tabRegionPrefix = "tab-"
+
+// createTabBar shows the open conversations above the chat view.
+func (app *TerminalApp) createTabBar() *tview.TextView {
+	bar := tview.NewTextView().SetDynamicColors(true).SetRegions(true).SetWrap(false)
+	bar.SetHighlightedFunc(func(added, removed, remaining []string) {
+		if len(added) == 0 {
+			return
+		}
+		if index, err := strconv.Atoi(strings.TrimPrefix(added[0], tabRegionPrefix)); err == nil {
+			app.switchTab(index)
+		}
+	})
+	app.tabBar = bar
+	return bar
+}
+
+// openTab adds a conversation to the tab bar, open tabs keep their place.
+func (app *TerminalApp) openTab(peerId string) {
+	if peerId != "" && !slices.Contains(app.tabs, peerId) {
+		app.tabs = append(app.tabs, peerId)
+	}
+}
+
+// showTabs redraws the tab bar, it must run on the ui goroutine.
+func (app *TerminalApp) showTabs() {
+	if app.tabBar == nil {
+		return
+	}
+	unread := make(map[string]int)
+	online := make(map[string]bool)
+	for _, user := range app.data.AllUsers() {
+		unread[user.id] = user.unread
+		online[user.id] = true
+	}
+
+	selected := app.selectedUserId.Get()
+	var bar strings.Builder
+	for index, peerId := range app.tabs {
+		label := tview.Escape(app.data.GetUserDetails(peerId))
+		if index < 9 {
+			label = fmt.Sprintf("%d %s", index+1, label)
+		}
+		switch {
+		case peerId == selected:
+			label = "[" + activeTheme.SelectionText + ":" + activeTheme.Selection + "] " + label + " [-:-]"
+		case !online[peerId]:
+			label = " " + tag(activeTheme.Muted) + label + "[-] "
+		case unread[peerId] > 0:
+			label = fmt.Sprintf(" %s %s(%d)[-] ", label, tag(activeTheme.Unread), unread[peerId])
+		case peerId == app.splitPeer:
+			label = " " + tag(activeTheme.Accent) + label + "[-] "
+		default:
+			label = " " + label + " "
+		}
+		fmt.Fprintf(&bar, `["%s%d"]%s[""]│`, tabRegionPrefix, index, label)
+	}
+	app.tabBar.SetText(bar.String())
+	// a clicked tab can be clicked again
+	app.tabBar.Highlight()
+}
+
+// switchTab selects the conversation of a tab, counted from zero.
+func (app *TerminalApp) switchTab(index int) {
+	if index < 0 || index >= len(app.tabs) {
+		return
+	}
+	app.selectedUserId.Set(app.tabs[index])
+}
+
+// closeTab closes the selected conversation and selects its neighbour. The
+// history stays in the db, reopening the conversation shows it again.
+func (app *TerminalApp) closeTab() {
+	selected := app.selectedUserId.Get()
+	index := slices.Index(app.tabs, selected)
+	if index < 0 {
+		return
+	}
+	myId, _ := app.data.GetUserId()
+	if len(app.tabs) == 1 && selected == myId {
+		app.printSystemLine("the last tab can't be closed")
+		return
+	}
+	app.tabs = slices.Delete(app.tabs, index, index+1)
+
+	next := myId
+	if len(app.tabs) > 0 {
+		next = app.tabs[min(index, len(app.tabs)-1)]
+	}
+	// the split view would swap in the closed conversation
+	if next == app.splitPeer {
+		app.setSplit(false)
+	}
+	app.selectedUserId.Set(next)
+	app.showTabs()
+}
+
+// switchDraft shows the draft of the selected conversation in the composer,
+// the composer saves every change to the draft of draftPeer.
+func (app *TerminalApp) switchDraft(peerId string) {
+	if peerId == app.draftPeer {
+		return
+	}
+	app.draftPeer = peerId
+	app.recallIndex = 0
+	app.composer.SetText(app.data.Draft(peerId), true)
+}
+
+// conversationSelected follows the selection with tabs, drafts and the split
+// view. The split view never shows the selected conversation, selecting it
+// swaps the two.
+func (app *TerminalApp) conversationSelected(peerId string) {
+	previous := app.draftPeer
+	app.openTab(peerId)
+	app.switchDraft(peerId)
+	if app.splitPeer != "" && app.splitPeer == peerId {
+		app.showSplit(previous)
+	}
+	app.showTabs()
+}
+
+// toggleSplit shows a second conversation next to the selected one, the last
+// opened tab.
+func (app *TerminalApp) toggleSplit() {
+	if app.splitPeer != "" {
+		app.setSplit(false)
+		return
+	}
+	app.setSplit(true)
+}
+
+func (app *TerminalApp) setSplit(enabled bool) {
+	if !enabled {
+		if app.splitPeer == "" {
+			return
+		}
+		app.splitPeer = ""
+		app.conversations.RemoveItem(app.splitView)
+		app.focusManager.removeItem(paneSplit)
+		if app.splitView.HasFocus() {
+			app.restoreFocus()
+		}
+		app.showTabs()
+		return
+	}
+
+	selected := app.selectedUserId.Get()
+	other := ""
+	// the tab opened last, other than the selected one
+	for i := len(app.tabs) - 1; i >= 0; i-- {
+		if app.tabs[i] != selected {
+			other = app.tabs[i]
+			break
+		}
+	}
+	if other == "" {
+		app.printSystemLine("open a second conversation to split the view")
+		return
+	}
+	app.conversations.AddItem(app.splitView, 0, 1, false)
+	app.focusManager.addItem(paneSplit, app.splitView.Box, 23)
+	app.showSplit(other)
+}
+
+// showSplit shows a conversation in the split view.
+func (app *TerminalApp) showSplit(peerId string) {
+	app.splitPeer = peerId
+	app.splitView.SetTitle(" " + tview.Escape(app.data.GetUserDetails(peerId)) + " ")
+	app.splitView.ShowConversation(peerId)
+	app.showTabs()
+}
+
+// tabKey switches tabs on Alt-1 to Alt-9, it reports whether the event was
+// used.
+func (app *TerminalApp) tabKey(event *tcell.EventKey) bool {
+	if event.Key() != tcell.KeyRune || event.Modifiers()&tcell.ModAlt == 0 {
+		return false
+	}
+	if r := event.Rune(); r >= '1' && r <= '9' {
+		app.switchTab(int(r - '1'))
+		return true
+	}
+	return false
+}
@@ -1,10 +1,13 @@
 package client
 
 import (
+	"errors"
 	"fmt"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
-	"log"
+	"log/slog"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -14,169 +17,419 @@ type TerminalApp struct {
 
 	app          *tview.Application
 	pages        *tview.Pages
-	chatTextView *tview.TextView
+	messageView  *MessageView
+	composer     *tview.TextArea
 	userList     *tview.List
+	blockedList  *tview.List
+	detailsView  *tview.TextView
+	infoPanel    *tview.TextView
 	focusManager Iterator[*tview.Box]
+	keymap       *Keymap
+	themes       *Themes
+	alerts       *Alerts
+	overlay      *overlay
+
+	// tabs are the open conversations in tab bar order
+	tabs   []string
+	tabBar *tview.TextView
+	// draftPeer is the conversation the composer text belongs to
+	draftPeer string
+	// recallIndex counts back from the newest sent message while Up and
+	// Down browse them, recalled is the text shown
+	recallIndex int
+	recalled    string
+	recallDraft string
+	// conversations holds the chat view and the split view
+	conversations *tview.Flex
+	splitView     *MessageView
+	splitPeer     string
+
+	// profiles are offered by the login form
+	profiles *Profiles
+
+	// kept to restyle them, tview.Pages can't list its pages
+	loginFrame *tview.Frame
+	dashboard  *tview.Flex
+	// the login form is kept for the next login after logout
+	loginForm     *tview.Form
+	loginStatus   *tview.TextView
+	passwordInput *tview.InputField
+
+	// listedUserIds follows the order of userList items
+	listedUserIds []string
 
 	selectedUserId SignalState[string]
+	// sessionDone is closed by logout, it stops the goroutines of the dashboard
+	sessionDone chan struct{}
 }
 
-func NewTerminalApplication(dataLayer ChatService, focusManager ActiveBoxManager, exitRequest chan bool) *tview.Application {
-	terminal := TerminalApp{
-		app:            tview.NewApplication(),
-		pages:          tview.NewPages(),
-		focusManager:   &focusManager,
-		data:           dataLayer,
-		selectedUserId: new(signalImplementation[string]),
-		chatTextView:   tview.NewTextView(),
-		userList:       tview.NewList(),
-		exitRequest:    exitRequest,
+func NewTerminalApplication(dataLayer ChatService, focusManager ActiveBoxManager, exitRequest chan bool, keymap *Keymap, themes *Themes, alerts *Alerts, clock *Clock, profiles *Profiles) *tview.Application {
+	activeClock = clock
+	terminal := &TerminalApp{
+		app:          tview.NewApplication(),
+		pages:        tview.NewPages(),
+		focusManager: &focusManager,
+		data:         dataLayer,
+		exitRequest:  exitRequest,
+		keymap:       keymap,
+		themes:       themes,
+		alerts:       alerts,
+		profiles:     profiles,
 	}
 
+	terminal.newSession()
+	terminal.enableMouse()
+
 	// login page
 	terminal.pages.AddPage("startup", terminal.loginPage(), true, true)
 	terminal.app.SetRoot(terminal.pages, true).SetFocus(terminal.pages)
 
+	// fail early instead of at registration
+	if err := dataLayer.CheckHealth(); err != nil {
+		slog.Warn("health check failed", "err", err)
+		terminal.pages.AddPage("unavailable", terminal.serverUnavailablePage(err), true, true)
+	}
+
 	return terminal.app
 }
 
 func (app *TerminalApp) activateRouting() {
 	terminal := app.app
 	terminal.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyTab {
-			app.focusNextElement()
+		// overlays handle their own keys
+		if front, _ := app.pages.GetFrontPage(); front != "dashboard" {
+			return event
 		}
-		return event
+		action := app.keymap.Action(event)
+		if action == "" {
+			if app.tabKey(event) {
+				return nil
+			}
+			return event
+		}
+		// typed characters belong to the focused input
+		if isCharacterKey(event) && app.typing() {
+			return event
+		}
+		app.runAction(action)
+		return nil
 	})
 }
 
-func (app *TerminalApp) loginPage() *tview.Frame {
-	flex := tview.NewFlex().SetDirection(tview.FlexRow)
-	flex.SetBorderPadding(0, 0, 3, 3)
+// typing reports whether the composer has the focus. Panes are focused through
+// their boxes, so the focused primitive can't tell.
+func (app *TerminalApp) typing() bool {
+	return app.composer != nil && app.composer.HasFocus()
+}
 
-	startButton := tview.NewButton("hi")
-	startButton.SetDisabled(true)
-	startButton.SetSelectedFunc(func() {
-		app.pages.AddAndSwitchToPage("dashboard", app.dashboardPage(), true)
-	})
+func (app *TerminalApp) runAction(action string) {
+	slog.Debug("key action", "action", action)
+	switch action {
+	case ActionNextPane:
+		app.focusNextElement()
+	case ActionPrevPane:
+		app.focusPrevElement()
+	case ActionNextUnread:
+		app.selectNextUnread()
+	case ActionSearch:
+		app.showSearch("")
+	case ActionQuit:
+		app.app.Stop()
+	case ActionHelp:
+		app.showHelp()
+	case ActionDnd:
+		app.toggleDoNotDisturb()
+	case ActionCloseTab:
+		app.closeTab()
+	case ActionSplit:
+		app.toggleSplit()
+	case ActionLogout:
+		app.logout()
+	case ActionRelativeTime:
+		app.toggleRelativeTime()
+	}
+}
 
-	usernameInput := tview.NewInputField()
-	usernameInput.SetLabel("Username:")
-	usernameInput.SetBorderPadding(0, 0, 2, 2)
+// newSession creates the widgets and state of a dashboard, logout replaces
+// them for the next user.
+func (app *TerminalApp) newSession() {
+	app.selectedUserId = NewSignal("")
+	app.sessionDone = make(chan struct{})
+	app.userList = tview.NewList()
+	app.blockedList = tview.NewList()
+	app.detailsView = tview.NewTextView()
+	app.messageView = NewMessageView(app.data.ReadMessagesPage)
+	app.splitView = NewMessageView(app.data.ReadMessagesPage)
+	app.composer = nil
+	app.tabs, app.tabBar = nil, nil
+	app.draftPeer, app.recallIndex, app.recalled, app.recallDraft = "", 0, "", ""
+	app.conversations, app.splitPeer = nil, ""
+	app.listedUserIds = nil
+	app.dashboard = nil
+}
 
-	errorText := tview.NewTextView()
-	errorText.SetTextAlign(tview.AlignCenter).SetTextColor(tcell.ColorOrangeRed)
+// logout deregisters, stops the dashboard and returns to the login page. The
+// process stays, another user can log in.
+func (app *TerminalApp) logout() {
+	app.closeOverlay()
+	close(app.sessionDone)
+	if err := app.data.Logout(); err != nil {
+		slog.Warn("logout failed", "err", err)
+	}
 
-	usernameInput.SetDoneFunc(func(key tcell.Key) {
-		if key == tcell.KeyEnter {
-			if usernameInput.GetText() == "" {
-				return
-			}
-			username := usernameInput.GetText()
-			err := app.data.Register(username)
-			if err != nil {
-				log.Println(err)
-				startButton.SetLabel("not available")
-				errorText.SetText(err.Error())
-				startButton.SetDisabled(true)
-			} else {
-				errorText.SetText("")
-				startButton.SetDisabled(false)
-				startButton.SetLabel("enter to start")
-				app.app.SetFocus(startButton)
-			}
-		}
-	})
+	app.pages.RemovePage("dashboard")
+	for _, pane := range []string{paneUsers, paneMessages, paneComposer, paneSplit, paneBlocked} {
+		app.focusManager.removeItem(pane)
+	}
+	app.newSession()
+	app.alerts.RestoreTitle()
+	app.showLogin()
+}
+
+// toggleDoNotDisturb silences or restores the alerts of new messages.
+func (app *TerminalApp) toggleDoNotDisturb() {
+	if app.alerts.ToggleDoNotDisturb() {
+		app.printSystemLine("do not disturb on, alerts are silenced")
+	} else {
+		app.printSystemLine("do not disturb off")
+	}
+	app.showUpdatedList()
+}
+
+// toggleRelativeTime switches the message times between clock and relative
+// times.
+func (app *TerminalApp) toggleRelativeTime() {
+	if activeClock.ToggleRelative() {
+		app.printSystemLine("relative times on")
+	} else {
+		app.printSystemLine("relative times off")
+	}
+	app.refreshTimes()
+}
 
-	flex.AddItem(usernameInput, 2, 1, true)
-	flex.AddItem(startButton, 1, 1, true)
-	flex.AddItem(errorText, 1, 1, true)
+// refreshTimes rewraps the messages, relative times change as time passes.
+func (app *TerminalApp) refreshTimes() {
+	app.messageView.Invalidate()
+	app.splitView.Invalidate()
+	app.refreshDetails()
+}
 
-	frame := tview.NewFrame(flex).
-		SetBorders(2, 2, 2, 2, 4, 4).
-		AddText("Simple grpc server chat with terminal client", true, tview.AlignLeft, tcell.ColorWhite).
-		AddText("github.com/olgierdjw", true, tview.AlignCenter, tcell.ColorGreen).
-		AddText("Go. Protobuf. gRPC. tview.", true, tview.AlignRight, tcell.ColorWhite)
+// selectNextUnread opens the first conversation with new messages after the
+// selected one, in list order.
+func (app *TerminalApp) selectNextUnread() {
+	unread := app.data.UnreadCounts().Get()
+	listed := len(app.listedUserIds)
+	current := slices.Index(app.listedUserIds, app.selectedUserId.Get())
+	for step := 1; step <= listed; step++ {
+		id := app.listedUserIds[(current+step+listed)%listed]
+		if unread[id] > 0 {
+			app.selectedUserId.Set(id)
+			return
+		}
+	}
+}
 
-	return frame
+// applyTheme switches the theme and restyles the pages built so far.
+func (app *TerminalApp) applyTheme(name string) error {
+	if err := app.themes.Select(name); err != nil {
+		return err
+	}
+	restyle(app.pages)
+	restyle(app.loginFrame)
+	app.styleLoginFrame()
+	if app.dashboard != nil {
+		restyle(app.dashboard)
+		app.showInfo()
+		app.showUpdatedList()
+		app.showBlockedList()
+	}
+	return nil
 }
 
 func (app *TerminalApp) focusNextElement() {
-	app.app.SetFocus(app.focusManager.next())
+	app.focusBox(app.focusManager.next())
+}
+
+func (app *TerminalApp) focusPrevElement() {
+	app.focusBox(app.focusManager.prev())
+}
+
+// focusPane focuses a dashboard pane by name, see the pane constants.
+func (app *TerminalApp) focusPane(name string) {
+	if box, ok := app.focusManager.focus(name); ok {
+		app.focusBox(box)
+	}
+}
+
+// restoreFocus gives the focus back to the current pane after an overlay.
+func (app *TerminalApp) restoreFocus() {
+	app.focusBox(app.focusManager.getCurrent())
+}
+
+func (app *TerminalApp) focusBox(box *tview.Box) {
+	if box != nil {
+		app.app.SetFocus(box)
+	}
 }
 
 func (app *TerminalApp) dashboardPage() *tview.Flex {
 	app.activateRouting()
 
 	left := app.createOnlineUsersPanel()
-	app.focusManager.addItem(left.Box, 10)
+	app.focusManager.addItem(paneUsers, left.Box, 10)
 
 	center := app.createCenterFlex()
 
-	right := tview.NewBox().SetBorder(true).SetTitle(" Right ")
-	app.focusManager.addItem(right, 30)
+	blocked := app.createBlockedUsersPanel()
+	app.focusManager.addItem(paneBlocked, blocked.Box, 30)
+
+	right := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(app.createDetailsPanel(), 0, 1, false).
+		AddItem(blocked, 8, 0, false)
 
-	return tview.NewFlex().
+	dashboard := tview.NewFlex().
 		AddItem(left, 30, 3, true).
 		AddItem(center, 0, 5, false).
 		AddItem(right, 0, 2, false)
+	restyle(dashboard)
+	return dashboard
 }
 
 func (app *TerminalApp) showUpdatedList() {
+	// the highlighted user stays highlighted when the order changes
+	var highlighted string
+	if index := app.userList.GetCurrentItem(); index >= 0 && index < len(app.listedUserIds) {
+		highlighted = app.listedUserIds[index]
+	}
 
 	// empty list
 	app.userList.Clear()
+	app.listedUserIds = app.listedUserIds[:0]
 
-	freeIndex := 1
-	for _, user := range app.data.AllUsers() {
+	myId, err := app.data.GetUserId()
+	if err != nil {
+		slog.Error("user list not updated", "err", err)
+		return
+	}
 
-		// user description
+	// users come sorted, only "me" is moved to the top
+	users := app.data.AllUsers()
+	if me := slices.IndexFunc(users, func(user User) bool { return user.id == myId }); me > 0 {
+		user := users[me]
+		users = slices.Insert(slices.Delete(users, me, me+1), 0, user)
+	}
+
+	for index, user := range users {
 		id := user.id
-		description := fmt.Sprintf("%s ", id[:5])
-		if user.notification {
-			description += "[red::bl](NEW MESSAGE)[-:-:-:-]"
+		name := tview.Escape(user.label())
+		if user.displayName != "" {
+			name += " " + tag(activeTheme.Muted) + tview.Escape(user.username) + "[-]"
 		}
-
-		// insertion order
-		var index int
-		if user.id == app.data.GetUserId() {
-			index = 0
+		description := describeListedUser(user)
+		if id == myId {
 			description = "me"
-		} else {
-			index = freeIndex
-			freeIndex++
+		}
+		if user.favourite {
+			name = tag(activeTheme.Accent) + "★[-] " + name
 		}
 
-		// append
-		app.userList.InsertItem(index, user.username, description, rune('a'+index), func() {
-			log.Printf("list option selected, username <%s>\n", user.username)
-			app.selectedUserId.pushValue(id)
-			app.focusNextElement()
+		app.listedUserIds = append(app.listedUserIds, id)
+		app.userList.AddItem(name, description, listShortcut(index), func() {
+			slog.Debug("list option selected", "username", user.username)
+			app.selectedUserId.Set(id)
+			app.focusPane(paneComposer)
 		})
+	}
+	if index := slices.Index(app.listedUserIds, highlighted); index >= 0 {
+		app.userList.SetCurrentItem(index)
+	}
+
+	totalUnread := app.data.TotalUnread().Get()
+	var notes []string
+	if totalUnread > 0 {
+		notes = append(notes, fmt.Sprintf("%d new", totalUnread))
+	}
+	if app.alerts.DoNotDisturb() {
+		notes = append(notes, "dnd")
+	}
+	if len(notes) > 0 {
+		app.userList.SetTitle(" Online users (" + strings.Join(notes, ", ") + ") ")
+	} else {
+		app.userList.SetTitle(" Online users ")
+	}
+	username, _ := app.data.GetUsername()
+	app.alerts.SetUnread(username, totalUnread)
+	app.showTabs()
+	app.showTitles()
+	app.refreshDetails()
+	slog.Debug("user list updated")
+}
+
+// showTitles names the conversations of the chat and split views, the names
+// change with nicknames.
+func (app *TerminalApp) showTitles() {
+	app.messageView.SetTitle(" Chat with " + tview.Escape(app.data.GetUserDetails(app.selectedUserId.Get())) + " ")
+	if app.splitPeer != "" {
+		app.splitView.SetTitle(" " + tview.Escape(app.data.GetUserDetails(app.splitPeer)) + " ")
+	}
+}
+
+// describeListedUser is the second line of a user list item: the short id,
+// the unread count and the time of the last message.
+func describeListedUser(user User) string {
+	description := fmt.Sprintf("%.5s", user.id)
+	if user.unread > 0 {
+		description += fmt.Sprintf(" %s%d new[-::B]", tag(activeTheme.Unread+"::b"), user.unread)
+	}
+	if !user.lastActivity.IsZero() {
+		description += " " + tag(activeTheme.Muted) + activeClock.Short(user.lastActivity) + "[-]"
+	}
+	return description
+}
+
+// pinHighlightedUser toggles the favourite mark, favourites are listed first.
+func (app *TerminalApp) pinHighlightedUser() {
+	index := app.userList.GetCurrentItem()
+	if index < 0 || index >= len(app.listedUserIds) {
+		return
+	}
+	app.data.ToggleFavourite(app.listedUserIds[index])
+	app.showUpdatedList()
+}
 
+// listShortcut gives the first users a letter, the others are reached with
+// the arrow keys.
+func listShortcut(index int) rune {
+	if index >= 26 {
+		return 0
 	}
-	log.Println("user list updated")
+	return rune('a' + index)
 }
 
 func (app *TerminalApp) createOnlineUsersPanel() *tview.List {
 	list := app.userList
 	list.SetBorder(true)
-	list.SetTitle(" Online users ")
 
 	list.SetSelectedFunc(func(index int, username string, shortId string, r rune) {
-		log.Println("SELECTED LIST ITEM", username)
+		slog.Debug("list item selected", "username", username)
 	})
 
-	list.SetSelectedBackgroundColor(tcell.ColorLightCoral)
-
 	app.showUpdatedList()
 
 	// first selected user
-	app.selectedUserId.pushValue(app.data.GetUserId())
+	myId, _ := app.data.GetUserId()
+	app.selectedUserId.Set(myId)
 
-	// ignore Tab key
+	// ignore Tab key, Ctrl-B blocks the highlighted user, Ctrl-P pins it
 	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyTab {
+		switch event.Key() {
+		case tcell.KeyTab:
+			return nil
+		case tcell.KeyCtrlB:
+			app.blockHighlightedUser()
+			return nil
+		case tcell.KeyCtrlP:
+			app.pinHighlightedUser()
 			return nil
 		}
 		return event
@@ -185,18 +438,32 @@ func (app *TerminalApp) createOnlineUsersPanel() *tview.List {
 	// handle list updates
 	go func() {
 		reloadUsers := app.data.OnlineUserChangedNotification()
+		unread, unsubscribe := app.data.UnreadCounts().Subscribe()
+		defer unsubscribe()
+		done := app.sessionDone
 		for {
-			<-reloadUsers
+			select {
+			case <-done:
+				return
+			case <-unread:
+				app.app.QueueUpdateDraw(app.showUpdatedList)
+				continue
+			case _, ok := <-reloadUsers:
+				if !ok {
+					return
+				}
+			}
 
 			// get rid of focus on empty user
-			if !app.data.CanChatWith(app.selectedUserId.getCurrentValue()) {
-				log.Println("selected user is offline")
-				app.selectedUserId.pushValue(app.data.GetUserId())
+			if !app.data.CanChatWith(app.selectedUserId.Get()) {
+				slog.Debug("selected user is offline")
+				app.selectedUserId.Set(myId)
 			}
 
 			// draw updated list
 			app.app.QueueUpdateDraw(func() {
 				app.showUpdatedList()
+				app.showBlockedList()
 			})
 		}
 	}()
@@ -206,128 +473,344 @@ func (app *TerminalApp) createOnlineUsersPanel() *tview.List {
 
 func (app *TerminalApp) createCenterFlex() *tview.Flex {
 	messages := app.createMessagePanel()
-	app.focusManager.addItem(messages.Box, 22)
+	app.focusManager.addItem(paneMessages, messages.Box, 22)
 
 	chat := app.createNewMessagePanel()
-	app.focusManager.addItem(chat.Box, 21)
+	app.focusManager.addItem(paneComposer, chat.Box, 21)
+
+	app.splitView.SetBorder(true)
+	app.conversations = tview.NewFlex().AddItem(messages, 0, 1, true)
 
 	flex := tview.NewFlex().SetDirection(tview.FlexRow).
 		AddItem(app.createInfoPanel(), 4, 1, false).
-		AddItem(messages, 0, 5, true).
-		AddItem(chat, 3, 1, true)
+		AddItem(app.createTabBar(), 1, 0, false).
+		AddItem(app.conversations, 0, 5, true).
+		AddItem(chat, composerHeight("", 0), 0, true)
+
+	// grow with the draft, the draft is saved as it is typed
+	chat.SetChangedFunc(func() {
+		if app.draftPeer != "" {
+			app.data.SaveDraft(app.draftPeer, chat.GetText())
+		}
+		_, _, width, _ := chat.GetInnerRect()
+		width -= tview.TaggedStringWidth(chat.GetLabel())
+		height := composerHeight(chat.GetText(), width)
+		flex.ResizeItem(chat, height, 0)
+		// scrolled while it was smaller, the whole draft fits now
+		if height < composerMaxRows+2 {
+			chat.SetOffset(0, 0)
+		}
+	})
 	return flex
 }
 
 func (app *TerminalApp) createInfoPanel() *tview.TextView {
-	infoPanel := tview.NewTextView()
+	infoPanel := tview.NewTextView().SetDynamicColors(true)
 	infoPanel.SetBorder(true)
-	fmt.Fprintf(infoPanel, "username: %s, id: %s\n", app.data.GetUsername(), app.data.GetUserId()[:5])
-	fmt.Fprint(infoPanel, "use TAB to navigate")
+	app.infoPanel = infoPanel
+	app.showInfo()
+
+	// follow the connection, a lost one is only left by logging out
+	go func() {
+		connection, unsubscribe := app.data.Connection().Subscribe()
+		defer unsubscribe()
+		done := app.sessionDone
+		for {
+			select {
+			case <-done:
+				return
+			case state := <-connection:
+				app.app.QueueUpdateDraw(func() {
+					app.showInfo()
+					if state == ConnectionLost {
+						app.printError("connection lost, log out to connect again")
+					}
+				})
+			}
+		}
+	}()
 	return infoPanel
 }
 
-func timeFromTimeout(messageTime time.Time) string {
-	currentTime := messageTime.In(time.Now().Location())
-	return currentTime.Format("15:04")
+// showInfo shows who is logged in and the connection, it changes with
+// nicknames.
+func (app *TerminalApp) showInfo() {
+	app.infoPanel.Clear()
+	username, _ := app.data.GetUsername()
+	myId, _ := app.data.GetUserId()
+	fmt.Fprintf(app.infoPanel, "username: %s", tview.Escape(username))
+	if displayName := app.data.GetDisplayName(); displayName != "" {
+		fmt.Fprintf(app.infoPanel, " (%s)", tview.Escape(displayName))
+	}
+	fmt.Fprintf(app.infoPanel, ", id: %.5s, ", myId)
+	state := app.data.Connection().Get()
+	stateColor := activeTheme.Online
+	if state != Connected {
+		stateColor = activeTheme.Error
+	}
+	fmt.Fprintf(app.infoPanel, "%s%s%s\n", tag(stateColor), state, tag(activeTheme.Text))
+	fmt.Fprint(app.infoPanel, "/help lists commands")
+	if key := app.keymap.Key(ActionHelp); key != "" {
+		fmt.Fprintf(app.infoPanel, ", %s lists keys", tview.Escape(key))
+	}
 }
 
-func printMessage(chat *tview.TextView, printableMessage *DbMessage) {
-	var prefix string
+// formatMessage returns the time and direction prefix and the text, the chat
+// view indents wrapped and following lines of the text under the prefix.
+func formatMessage(printableMessage *DbMessage) (prefix, text string) {
+	var direction string
 
 	if printableMessage.incoming {
-		prefix = "[white][>>]"
+		direction = tag(activeTheme.Incoming) + "[>>]" + tag(activeTheme.Text)
 	} else {
-		prefix = "[grey][<<][white]"
+		direction = tag(activeTheme.Outgoing) + "[<<]" + tag(activeTheme.Text)
 	}
 
-	hhss := timeFromTimeout(printableMessage.time.AsTime())
+	hhss := activeClock.Message(printableMessage.time.AsTime())
 
-	fmt.Fprint(chat, hhss+" "+prefix+" "+printableMessage.text+"\n")
+	return hhss + " " + direction + " ", renderMarkdown(printableMessage.text)
 }
 
-func (app *TerminalApp) createMessagePanel() *tview.TextView {
-	textView := app.chatTextView
-	textView.SetDynamicColors(true)
-	textView.SetTitle("Chat")
-	textView.SetBorder(true)
+func formatNotice(kind noticeKind, text string) string {
+	textColor := activeTheme.Text
+	switch kind {
+	case noticeWarning:
+		textColor = activeTheme.Warning
+	case noticeError:
+		textColor = activeTheme.Error
+	}
+	return tag(activeTheme.Muted) + "--" + tag(textColor) + " " + text + tag(activeTheme.Text)
+}
 
-	// display messages in the console even when it's not the active window
-	textView.SetChangedFunc(func() {
-		app.app.Draw()
-		textView.ScrollToEnd()
-	})
+// relativeRefresh is how often relative times are updated.
+const relativeRefresh = 30 * time.Second
 
-	fmt.Fprintln(textView, "loading...")
+func (app *TerminalApp) createMessagePanel() *MessageView {
+	messageView := app.messageView
+	messageView.SetTitle("Chat")
+	messageView.SetBorder(true)
+	messageView.SetContextMenuFunc(app.showMessageMenu)
+	app.splitView.SetContextMenuFunc(app.showMessageMenu)
 
 	go func() {
-		selectedUserChannel := app.selectedUserId.getUpdateChannel()
+		selectedUserChannel, unsubscribe := app.selectedUserId.Subscribe()
+		defer unsubscribe()
+		// closed channels are set to nil, the stream ended with the connection
+		newMessages := app.data.NewMessageNotification()
+		announcements := app.data.AnnouncementNotification()
+		done := app.sessionDone
+		ticker := time.NewTicker(relativeRefresh)
+		defer ticker.Stop()
 		for {
 			select {
-			// show all messages on chat user change
+			case <-done:
+				return
+
+			// relative times move on
+			case <-ticker.C:
+				app.app.QueueUpdateDraw(func() {
+					if activeClock.IsRelative() {
+						app.refreshTimes()
+					}
+				})
+
+			// show the conversation on chat user change
 			case currentChatUser := <-selectedUserChannel:
-				textView.Clear()
-				textView.SetTitle(" Chat with " + app.data.GetUserDetails(currentChatUser) + " ")
-				previousMessages := app.data.ReadMessages(currentChatUser)
-				for _, mes := range previousMessages {
-					printMessage(textView, &mes)
-				}
 				app.app.QueueUpdateDraw(func() {
+					messageView.ShowConversation(currentChatUser)
+					app.conversationSelected(currentChatUser)
 					app.showUpdatedList()
 				})
 
 			// append all new incoming messages
-			case newMessage := <-app.data.NewMessageNotification():
-				currentlyPrintableMessage := newMessage.SenderId == app.selectedUserId.getCurrentValue()
-				if currentlyPrintableMessage {
-					printMessage(textView, &DbMessage{
-						incoming: true,
-						text:     newMessage.Message,
-						time:     newMessage.Time,
-					})
-				} else {
-					app.data.SendNotification(newMessage.SenderId)
-					app.app.QueueUpdateDraw(func() {
+			case newMessage, ok := <-newMessages:
+				if !ok {
+					newMessages = nil
+					continue
+				}
+				app.app.QueueUpdateDraw(func() {
+					currentlyPrintableMessage := newMessage.SenderId == app.selectedUserId.Get()
+					if currentlyPrintableMessage {
+						messageView.AppendMessage(DbMessage{
+							incoming: true,
+							text:     newMessage.Message,
+							time:     newMessage.Time,
+						})
+						// reorders the list by activity
+						app.showUpdatedList()
+					} else if newMessage.SenderId == app.splitPeer {
+						app.splitView.AppendMessage(DbMessage{
+							incoming: true,
+							text:     newMessage.Message,
+							time:     newMessage.Time,
+						})
 						app.showUpdatedList()
-					})
+					} else {
+						// the list follows the unread counts
+						app.data.SendNotification(newMessage.SenderId)
+						app.alerts.Message(app.data.GetUserDetails(newMessage.SenderId), newMessage.Message)
+					}
+				})
+
+			// operator messages go to whichever chat is open
+			case announcement, ok := <-announcements:
+				if !ok {
+					announcements = nil
+					continue
 				}
+				app.app.QueueUpdateDraw(func() {
+					hhss := activeClock.Message(announcement.Time.AsTime())
+					messageView.AppendNotice(noticeWarning, fmt.Sprintf("%s [!!] %s", hhss, tview.Escape(announcement.Text)))
+				})
 			}
 
 		}
 	}()
 
-	return textView
+	return messageView
 }
 
-func (app *TerminalApp) createNewMessagePanel() *tview.InputField {
-	messageInput := tview.NewInputField()
+// composerMaxRows bounds how far the composer grows with multi-line drafts.
+const composerMaxRows = 10
+
+func (app *TerminalApp) createNewMessagePanel() *tview.TextArea {
+	messageInput := tview.NewTextArea()
+	app.composer = messageInput
 	messageInput.SetLabel("Message:")
-	messageInput.SetLabelColor(tcell.ColorWhite)
+	messageInput.SetLabelStyle(labelStyle(false))
+	messageInput.SetPlaceholder(" Alt-Enter for a new line, Ctrl-E opens $EDITOR")
 	messageInput.SetBorder(true)
 
 	messageInput.SetFocusFunc(func() {
-		messageInput.SetLabelColor(tcell.ColorRed)
+		messageInput.SetLabelStyle(labelStyle(true))
 	})
 
 	messageInput.SetBlurFunc(func() {
-		messageInput.SetLabelColor(tcell.ColorWhite)
+		messageInput.SetLabelStyle(labelStyle(false))
 	})
 
-	messageInput.SetDoneFunc(func(key tcell.Key) {
-		if key == tcell.KeyEnter {
-			if messageInput.GetText() == "" {
-				return
+	messageInput.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEnter:
+			// the text area inserts a new line on a plain Enter
+			if event.Modifiers()&tcell.ModAlt != 0 {
+				return tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)
+			}
+			if app.sendComposed(messageInput.GetText()) {
+				messageInput.SetText("", false)
+			}
+			return nil
+		case tcell.KeyUp:
+			if app.recallSent(1) {
+				return nil
 			}
-			sendingTo := app.selectedUserId.getCurrentValue()
-			messageText := messageInput.GetText()
-			printable := app.data.SendMessage(sendingTo, messageText)
-			printMessage(app.chatTextView, &printable)
-			messageInput.SetText("")
+		case tcell.KeyDown:
+			if app.recallSent(-1) {
+				return nil
+			}
+		case tcell.KeyCtrlE:
+			text, err := app.composeInEditor(messageInput.GetText())
+			if err != nil {
+				slog.Warn("external editor failed", "err", err)
+				app.messageView.AppendNotice(noticeError, "editor failed: "+tview.Escape(err.Error()))
+				return nil
+			}
+			// keep the edited text if it can't be sent
+			messageInput.SetText(text, true)
+			if app.sendComposed(text) {
+				messageInput.SetText("", false)
+			}
+			return nil
 		}
+		return event
 	})
 
 	return messageInput
 }
 
+// recallSent browses the messages sent to the selected user, Up on the first
+// line starts with the newest. Down past the newest restores the draft.
+func (app *TerminalApp) recallSent(step int) bool {
+	text := app.composer.GetText()
+	if app.recallIndex == 0 || text != app.recalled {
+		// not browsing, or the recalled text was edited
+		row, _, _, _ := app.composer.GetCursor()
+		if step < 0 || row != 0 {
+			app.recallIndex = 0
+			return false
+		}
+		app.recallIndex, app.recallDraft = 0, text
+	}
+
+	sent := app.data.SentMessages(app.draftPeer)
+	index := app.recallIndex + step
+	if index > len(sent) {
+		// the oldest message stays
+		return app.recallIndex > 0
+	}
+	app.recallIndex = index
+	if index == 0 {
+		app.composer.SetText(app.recallDraft, true)
+		return true
+	}
+	app.recalled = sent[len(sent)-index]
+	app.composer.SetText(app.recalled, true)
+	return true
+}
+
+// composerHeight fits the composer to the wrapped lines of its text, borders
+// included.
+func composerHeight(text string, width int) int {
+	rows := 0
+	for _, line := range strings.Split(text, "\n") {
+		rows += max(1, len(tview.WordWrap(tview.Escape(line), max(width, 1))))
+	}
+	return min(rows, composerMaxRows) + 2
+}
+
+// sendComposed runs a command or sends the text to the selected user. It
+// reports whether the composer can be cleared.
+func (app *TerminalApp) sendComposed(messageText string) bool {
+	if strings.TrimSpace(messageText) == "" {
+		return false
+	}
+	if isCommand(messageText) {
+		// a command is no draft, and after /logout or a tab switch clearing
+		// the composer would no longer reach the draft it was typed into
+		if app.draftPeer != "" {
+			app.data.SaveDraft(app.draftPeer, "")
+		}
+		app.runCommand(messageText)
+		return true
+	}
+	return app.sendTo(app.selectedUserId.Get(), unescapeCommand(messageText))
+}
+
+// sendTo sends a message to a peer and shows it in the view of its
+// conversation, the selected or the split one. It reports whether the message
+// was sent.
+func (app *TerminalApp) sendTo(sendingTo, messageText string) bool {
+	printable, err := app.data.SendMessage(sendingTo, messageText)
+	var limited *RateLimitedError
+	if errors.As(err, &limited) {
+		app.messageView.AppendNotice(noticeWarning, fmt.Sprintf("slow down, you can send again in %s", limited.RetryAfter))
+		return false
+	}
+	if err != nil {
+		slog.Warn("message not sent", "receiver_id", sendingTo, "err", err)
+		app.messageView.AppendNotice(noticeError, "message not sent: "+tview.Escape(err.Error()))
+		return false
+	}
+	switch sendingTo {
+	case app.selectedUserId.Get():
+		app.messageView.AppendMessage(printable)
+	case app.splitPeer:
+		app.splitView.AppendMessage(printable)
+	}
+	app.showUpdatedList()
+	return true
+}
+
 func (app *TerminalApp) serverConnectionFailed() {
 	app.app.Stop()
 }
@@ -0,0 +1,321 @@
+package client
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"gopkg.in/yaml.v3"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Theme names the colours of the client. Values are tcell colour names such
+// as "lightcoral", "#rrggbb" or "default" for the terminal colour.
+type Theme struct {
+	Background         string `yaml:"background"`
+	ContrastBackground string `yaml:"contrast_background"`
+	Border             string `yaml:"border"`
+	Title              string `yaml:"title"`
+	Text               string `yaml:"text"`
+	SecondaryText      string `yaml:"secondary_text"`
+	TertiaryText       string `yaml:"tertiary_text"`
+	Muted              string `yaml:"muted"`
+	Incoming           string `yaml:"incoming"`
+	Outgoing           string `yaml:"outgoing"`
+	Selection          string `yaml:"selection"`
+	SelectionText      string `yaml:"selection_text"`
+	Focus              string `yaml:"focus"`
+	Unread             string `yaml:"unread"`
+	Online             string `yaml:"online"`
+	Accent             string `yaml:"accent"`
+	Error              string `yaml:"error"`
+	Warning            string `yaml:"warning"`
+	Highlight          string `yaml:"highlight"`
+	HighlightText      string `yaml:"highlight_text"`
+	Link               string `yaml:"link"`
+	Code               string `yaml:"code"`
+	CodeText           string `yaml:"code_text"`
+	Keyword            string `yaml:"keyword"`
+	String             string `yaml:"string"`
+	Number             string `yaml:"number"`
+	Comment            string `yaml:"comment"`
+}
+
+// DefaultTheme is used when the config names no theme.
+const DefaultTheme = "dark"
+
+// builtinThemes can't be replaced, user themes extend them through "base".
+var builtinThemes = map[string]Theme{
+	"dark": {
+		Background: "black", ContrastBackground: "blue", Border: "white", Title: "white",
+		Text: "white", SecondaryText: "yellow", TertiaryText: "green", Muted: "grey",
+		Incoming: "white", Outgoing: "grey", Selection: "lightcoral", SelectionText: "black",
+		Focus: "red", Unread: "red", Online: "green", Accent: "yellow",
+		Error: "red", Warning: "yellow", Highlight: "yellow", HighlightText: "black", Link: "blue",
+		Code: "orange", CodeText: "lightcyan", Keyword: "fuchsia", String: "green", Number: "aqua", Comment: "grey",
+	},
+	"light": {
+		Background: "white", ContrastBackground: "lightgrey", Border: "black", Title: "black",
+		Text: "black", SecondaryText: "navy", TertiaryText: "darkgreen", Muted: "grey",
+		Incoming: "black", Outgoing: "dimgrey", Selection: "lightblue", SelectionText: "black",
+		Focus: "red", Unread: "red", Online: "darkgreen", Accent: "navy",
+		Error: "red", Warning: "darkorange", Highlight: "yellow", HighlightText: "black", Link: "blue",
+		Code: "maroon", CodeText: "black", Keyword: "purple", String: "darkgreen", Number: "teal", Comment: "grey",
+	},
+	"high-contrast": {
+		Background: "black", ContrastBackground: "navy", Border: "yellow", Title: "yellow",
+		Text: "white", SecondaryText: "aqua", TertiaryText: "lime", Muted: "silver",
+		Incoming: "aqua", Outgoing: "yellow", Selection: "yellow", SelectionText: "black",
+		Focus: "aqua", Unread: "fuchsia", Online: "lime", Accent: "yellow",
+		Error: "red", Warning: "yellow", Highlight: "aqua", HighlightText: "black", Link: "aqua",
+		Code: "yellow", CodeText: "white", Keyword: "fuchsia", String: "lime", Number: "aqua", Comment: "silver",
+	},
+	"monochrome": {
+		Background: "black", ContrastBackground: "black", Border: "white", Title: "white",
+		Text: "white", SecondaryText: "white", TertiaryText: "white", Muted: "white",
+		Incoming: "white", Outgoing: "white", Selection: "white", SelectionText: "black",
+		Focus: "white", Unread: "white", Online: "white", Accent: "white",
+		Error: "white", Warning: "white", Highlight: "white", HighlightText: "black", Link: "white",
+		Code: "white", CodeText: "white", Keyword: "white", String: "white", Number: "white", Comment: "white",
+	},
+}
+
+// activeTheme colours the markup built by the formatting functions, it is only
+// changed on the ui goroutine.
+var activeTheme = builtinThemes[DefaultTheme]
+
+// tag returns the markup setting a foreground colour.
+func tag(color string) string {
+	return "[" + color + "]"
+}
+
+// highlightTag returns the markup of search matches and marked lines.
+func (t Theme) highlightTag() string {
+	return "[" + t.HighlightText + ":" + t.Highlight + "]"
+}
+
+func color(name string) tcell.Color {
+	if name == "default" {
+		return tcell.ColorDefault
+	}
+	return tcell.GetColor(name)
+}
+
+var hexColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// validate reports the first colour tcell doesn't know.
+func (t Theme) validate() error {
+	value := reflect.ValueOf(t)
+	for i := 0; i < value.NumField(); i++ {
+		name := value.Field(i).String()
+		if name == "default" || hexColorPattern.MatchString(name) {
+			continue
+		}
+		if _, ok := tcell.ColorNames[strings.ToLower(name)]; !ok {
+			key, _, _ := strings.Cut(value.Type().Field(i).Tag.Get("yaml"), ",")
+			return fmt.Errorf("%s: unknown colour %q", key, name)
+		}
+	}
+	return nil
+}
+
+// merge fills the colours a user theme leaves out from its base.
+func (t Theme) merge(base Theme) Theme {
+	value := reflect.ValueOf(&t).Elem()
+	baseValue := reflect.ValueOf(base)
+	for i := 0; i < value.NumField(); i++ {
+		if value.Field(i).String() == "" {
+			value.Field(i).Set(baseValue.Field(i))
+		}
+	}
+	return t
+}
+
+// userTheme is an entry of the themes file.
+type userTheme struct {
+	Base  string `yaml:"base"`
+	Theme `yaml:",inline"`
+}
+
+// Themes are the built-in themes and those of the ui.themes_file.
+type Themes struct {
+	themes  map[string]Theme
+	current string
+}
+
+// LoadThemes reads the optional themes file and selects a theme. The file maps
+// theme names to colours, missing colours come from the base theme:
+//
+//	solarized:
+//	  base: dark
+//	  background: "#002b36"
+func LoadThemes(path, selected string) (*Themes, error) {
+	themes := &Themes{themes: make(map[string]Theme, len(builtinThemes))}
+	for name, theme := range builtinThemes {
+		themes.themes[name] = theme
+	}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var file map[string]userTheme
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for name, theme := range file {
+			if _, ok := builtinThemes[name]; ok {
+				return nil, fmt.Errorf("%s: %s is a built-in theme", path, name)
+			}
+			if theme.Base == "" {
+				theme.Base = DefaultTheme
+			}
+			base, ok := builtinThemes[theme.Base]
+			if !ok {
+				return nil, fmt.Errorf("%s: theme %s: unknown base %q", path, name, theme.Base)
+			}
+			merged := theme.Theme.merge(base)
+			if err := merged.validate(); err != nil {
+				return nil, fmt.Errorf("%s: theme %s: %w", path, name, err)
+			}
+			themes.themes[name] = merged
+		}
+	}
+
+	if selected == "" {
+		selected = DefaultTheme
+	}
+	if err := themes.Select(selected); err != nil {
+		return nil, err
+	}
+	return themes, nil
+}
+
+// Select makes a theme current and active, it doesn't restyle existing widgets.
+func (t *Themes) Select(name string) error {
+	theme, ok := t.themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme %q, available: %s", name, strings.Join(t.Names(), ", "))
+	}
+	t.current = name
+	activeTheme = theme
+	setTviewStyles(theme)
+	return nil
+}
+
+// Current returns the name of the selected theme.
+func (t *Themes) Current() string {
+	return t.current
+}
+
+// Names lists the themes alphabetically.
+func (t *Themes) Names() []string {
+	names := make([]string, 0, len(t.themes))
+	for name := range t.themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// setTviewStyles sets the colours new widgets start with.
+func setTviewStyles(theme Theme) {
+	tview.Styles = tview.Theme{
+		PrimitiveBackgroundColor:    color(theme.Background),
+		ContrastBackgroundColor:     color(theme.ContrastBackground),
+		MoreContrastBackgroundColor: color(theme.Selection),
+		BorderColor:                 color(theme.Border),
+		TitleColor:                  color(theme.Title),
+		GraphicsColor:               color(theme.Border),
+		PrimaryTextColor:            color(theme.Text),
+		SecondaryTextColor:          color(theme.SecondaryText),
+		TertiaryTextColor:           color(theme.TertiaryText),
+		InverseTextColor:            color(theme.Accent),
+		ContrastSecondaryTextColor:  color(theme.SecondaryText),
+	}
+}
+
+// labelStyle marks the label of the focused input.
+func labelStyle(focused bool) tcell.Style {
+	if focused {
+		return tcell.StyleDefault.Foreground(color(activeTheme.Focus))
+	}
+	return tcell.StyleDefault.Foreground(color(activeTheme.Text))
+}
+
+// restyle applies the active theme to a widget tree built with another one.
+func restyle(p tview.Primitive) {
+	theme := activeTheme
+	styleBox := func(box *tview.Box) {
+		box.SetBackgroundColor(color(theme.Background))
+		box.SetBorderColor(color(theme.Border))
+		box.SetTitleColor(color(theme.Title))
+	}
+	text := tcell.StyleDefault.Background(color(theme.Background)).Foreground(color(theme.Text))
+
+	switch widget := p.(type) {
+	case *tview.Flex:
+		styleBox(widget.Box)
+		for i := 0; i < widget.GetItemCount(); i++ {
+			restyle(widget.GetItem(i))
+		}
+	case *tview.Frame:
+		styleBox(widget.Box)
+		restyle(widget.GetPrimitive())
+	case *tview.Pages:
+		// pages can't be listed, the app restyles the ones it keeps
+		styleBox(widget.Box)
+	case *tview.List:
+		styleBox(widget.Box)
+		widget.SetMainTextColor(color(theme.Text)).
+			SetSecondaryTextColor(color(theme.TertiaryText)).
+			SetShortcutColor(color(theme.SecondaryText)).
+			SetSelectedBackgroundColor(color(theme.Selection)).
+			SetSelectedTextColor(color(theme.SelectionText))
+	case *tview.TextView:
+		styleBox(widget.Box)
+		widget.SetTextStyle(text)
+	case *tview.TextArea:
+		styleBox(widget.Box)
+		widget.SetTextStyle(text).
+			SetPlaceholderStyle(text.Foreground(color(theme.Muted))).
+			SetLabelStyle(labelStyle(widget.HasFocus()))
+	case *tview.InputField:
+		styleBox(widget.Box)
+		widget.SetLabelColor(color(theme.SecondaryText)).
+			SetFieldBackgroundColor(color(theme.ContrastBackground)).
+			SetFieldTextColor(color(theme.Text)).
+			SetPlaceholderTextColor(color(theme.Muted))
+	case *tview.Button:
+		styleBox(widget.Box)
+		widget.SetStyle(tcell.StyleDefault.Background(color(theme.ContrastBackground)).Foreground(color(theme.Text))).
+			SetActivatedStyle(tcell.StyleDefault.Background(color(theme.Selection)).Foreground(color(theme.SelectionText))).
+			SetDisabledStyle(tcell.StyleDefault.Background(color(theme.ContrastBackground)).Foreground(color(theme.Muted)))
+	case *tview.Form:
+		styleBox(widget.Box)
+		widget.SetLabelColor(color(theme.SecondaryText)).
+			SetFieldBackgroundColor(color(theme.ContrastBackground)).
+			SetFieldTextColor(color(theme.Text)).
+			SetButtonStyle(tcell.StyleDefault.Background(color(theme.ContrastBackground)).Foreground(color(theme.Text))).
+			SetButtonActivatedStyle(tcell.StyleDefault.Background(color(theme.Selection)).Foreground(color(theme.SelectionText))).
+			SetButtonDisabledStyle(tcell.StyleDefault.Background(color(theme.ContrastBackground)).Foreground(color(theme.Muted)))
+		for i := 0; i < widget.GetFormItemCount(); i++ {
+			if dropDown, ok := widget.GetFormItem(i).(*tview.DropDown); ok {
+				dropDown.SetListStyles(text.Background(color(theme.ContrastBackground)),
+					tcell.StyleDefault.Background(color(theme.Selection)).Foreground(color(theme.SelectionText)))
+			}
+		}
+	case *tview.Modal:
+		widget.SetBackgroundColor(color(theme.ContrastBackground)).
+			SetTextColor(color(theme.Text)).
+			SetButtonBackgroundColor(color(theme.Background)).
+			SetButtonTextColor(color(theme.Text))
+	case *MessageView:
+		styleBox(widget.Box)
+		widget.Invalidate()
+	}
+}
@@ -0,0 +1,295 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Config is the effective configuration of both the server and the client.
+// Values are layered: defaults, then the YAML file, then CHAT_* environment
+// variables, then command line flags.
+type Config struct {
+	Server ServerConfig `yaml:"server"`
+	Client ClientConfig `yaml:"client"`
+	UI     UIConfig     `yaml:"ui"`
+	Log    LogConfig    `yaml:"log"`
+}
+
+type ServerConfig struct {
+	Listen     string `yaml:"listen"`
+	Reflection bool   `yaml:"reflection"`
+	// QueueSize is the number of updates buffered for each connected user.
+	QueueSize int `yaml:"queue_size"`
+	// QueueTimeout is how long a sender waits for space in a full queue.
+	QueueTimeout time.Duration `yaml:"queue_timeout"`
+	// AdminToken enables the Admin service, it stays disabled when empty.
+	AdminToken string `yaml:"admin_token"`
+	// Accounts reserve usernames for those who know the password, the values
+	// are printed by the hash-password subcommand. Other usernames stay open.
+	Accounts  map[string]string `yaml:"accounts"`
+	RateLimit RateLimitConfig   `yaml:"rate_limit"`
+	TLS       TLSConfig         `yaml:"tls"`
+	Storage   StorageConfig     `yaml:"storage"`
+}
+
+// RateLimitConfig sets a token bucket per client and method. Methods are full
+// grpc names such as /RegisterUser/SendDirectMessage.
+type RateLimitConfig struct {
+	Default RateLimit            `yaml:"default"`
+	Methods map[string]RateLimit `yaml:"methods"`
+}
+
+// RateLimit allows Rate calls per second with bursts of up to Burst calls.
+// A zero rate disables the limit.
+type RateLimit struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+type ClientConfig struct {
+	ServerAddress string `yaml:"server_address"`
+	// QueueSize is the number of server updates buffered for the ui.
+	QueueSize      int           `yaml:"queue_size"`
+	HealthTimeout  time.Duration `yaml:"health_timeout"`
+	RequestTimeout time.Duration `yaml:"request_timeout"`
+	// AdminToken is sent by the admin subcommand.
+	AdminToken string        `yaml:"admin_token"`
+	TLS        TLSConfig     `yaml:"tls"`
+	Storage    StorageConfig `yaml:"storage"`
+	// Profiles are offered on the login page next to ServerAddress.
+	Profiles []ServerProfile `yaml:"profiles"`
+	// ProfilesFile keeps the profiles saved from the login page, they follow
+	// Profiles. Saving is disabled when empty.
+	ProfilesFile string `yaml:"profiles_file"`
+}
+
+// ServerProfile is a saved server with the username to log in as.
+type ServerProfile struct {
+	Name          string    `yaml:"name"`
+	ServerAddress string    `yaml:"server_address"`
+	Username      string    `yaml:"username"`
+	TLS           TLSConfig `yaml:"tls"`
+}
+
+// ServerProfiles lists the configured server as "default" followed by the
+// saved profiles.
+func (c ClientConfig) ServerProfiles() []ServerProfile {
+	return append([]ServerProfile{{Name: "default", ServerAddress: c.ServerAddress, TLS: c.TLS}}, c.Profiles...)
+}
+
+type TLSConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// CertFile and KeyFile are the server key pair.
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// CAFile verifies the server, system roots are used when empty.
+	CAFile     string `yaml:"ca_file"`
+	ServerName string `yaml:"server_name"`
+}
+
+type StorageConfig struct {
+	Backend string `yaml:"backend"`
+	Path    string `yaml:"path"`
+}
+
+type UIConfig struct {
+	// Theme is a built-in theme (dark, light, high-contrast, monochrome) or
+	// one of ThemesFile.
+	Theme      string `yaml:"theme"`
+	ThemesFile string `yaml:"themes_file"`
+	// Keys binds client actions to keys such as "Tab", "Ctrl-F", "Alt-n" or
+	// "?", an empty key unbinds the action.
+	Keys   map[string]string `yaml:"keys"`
+	Alerts AlertsConfig      `yaml:"alerts"`
+	Time   TimeConfig        `yaml:"time"`
+}
+
+// TimeConfig sets how the client shows times.
+type TimeConfig struct {
+	// Clock is "24h" or "12h".
+	Clock string `yaml:"clock"`
+	// Timezone is an IANA name such as "Europe/Warsaw", the local zone is
+	// used when empty.
+	Timezone string `yaml:"timezone"`
+	// Relative starts the chat view with times such as "5m ago".
+	Relative bool `yaml:"relative"`
+}
+
+// AlertsConfig sets how messages to conversations that aren't open are
+// announced.
+type AlertsConfig struct {
+	Bell bool `yaml:"bell"`
+	// Title shows the unread count in the terminal title.
+	Title bool `yaml:"title"`
+	// NotifyCommand is run with the sender and a preview of the message as
+	// its last two arguments, e.g. "notify-send".
+	NotifyCommand string `yaml:"notify_command"`
+	// DoNotDisturb starts the client with bell and command silenced.
+	DoNotDisturb bool `yaml:"do_not_disturb"`
+}
+
+type LogConfig struct {
+	// Destination is stdout, stderr, discard or a file path.
+	Destination string `yaml:"destination"`
+	Level       string `yaml:"level"`
+	Format      string `yaml:"format"`
+}
+
+const (
+	StorageMemory = "memory"
+	// StorageFile keeps data in the file at Path.
+	StorageFile = "file"
+)
+
+// Default returns the built-in configuration.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Listen:       ":8898",
+			QueueSize:    100,
+			QueueTimeout: 2 * time.Second,
+			RateLimit: RateLimitConfig{
+				Default: RateLimit{Rate: 10, Burst: 20},
+				Methods: map[string]RateLimit{
+					"/RegisterUser/SendDirectMessage": {Rate: 2, Burst: 5},
+				},
+			},
+			Storage: StorageConfig{Backend: StorageMemory},
+		},
+		Client: ClientConfig{
+			ServerAddress:  "localhost:8898",
+			QueueSize:      100,
+			HealthTimeout:  3 * time.Second,
+			RequestTimeout: 10 * time.Second,
+			Storage:        StorageConfig{Backend: StorageMemory},
+			ProfilesFile:   DefaultProfilesPath(),
+		},
+		UI: UIConfig{
+			Theme: "dark",
+			Keys: map[string]string{
+				"next_pane":     "Tab",
+				"prev_pane":     "Backtab",
+				"next_unread":   "Ctrl-N",
+				"search":        "Ctrl-F",
+				"quit":          "Ctrl-Q",
+				"help":          "?",
+				"dnd":           "Ctrl-D",
+				"close_tab":     "Alt-w",
+				"split":         "Alt-s",
+				"logout":        "Alt-l",
+				"relative_time": "Alt-t",
+			},
+			Alerts: AlertsConfig{Bell: true, Title: true},
+			Time:   TimeConfig{Clock: "24h"},
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "text",
+		},
+	}
+}
+
+// DefaultPath is the config file used when none is given explicitly.
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "chat", "config.yaml")
+}
+
+// LoadFile merges the YAML file into cfg. A missing file is not an error
+// unless it was requested explicitly.
+func LoadFile(cfg *Config, path string, required bool) error {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	if err := yaml.Unmarshal(content, cfg); err != nil {
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate rejects values the rest of the program can't work with.
+func (c *Config) Validate() error {
+	if c.Server.QueueSize < 1 || c.Client.QueueSize < 1 {
+		return errors.New("queue_size must be positive")
+	}
+	switch c.Server.Storage.Backend {
+	case StorageMemory:
+	case StorageFile:
+		if c.Server.Storage.Path == "" {
+			return errors.New("server file storage needs a path")
+		}
+	default:
+		return fmt.Errorf("unknown server storage backend %q", c.Server.Storage.Backend)
+	}
+	switch c.Client.Storage.Backend {
+	case StorageMemory:
+	case StorageFile:
+		if c.Client.Storage.Path == "" {
+			return errors.New("client file storage needs a path")
+		}
+	default:
+		return fmt.Errorf("unknown client storage backend %q", c.Client.Storage.Backend)
+	}
+	for method, limit := range c.Server.RateLimit.Methods {
+		if limit.Rate < 0 || (limit.Rate > 0 && limit.Burst < 1) {
+			return fmt.Errorf("rate limit for %s needs a positive burst", method)
+		}
+	}
+	if limit := c.Server.RateLimit.Default; limit.Rate < 0 || (limit.Rate > 0 && limit.Burst < 1) {
+		return errors.New("default rate limit needs a positive burst")
+	}
+	if (c.Server.TLS.CertFile == "") != (c.Server.TLS.KeyFile == "") {
+		return errors.New("server tls needs both cert_file and key_file")
+	}
+	if c.UI.Time.Clock != "24h" && c.UI.Time.Clock != "12h" {
+		return fmt.Errorf("ui.time.clock must be 24h or 12h, not %q", c.UI.Time.Clock)
+	}
+	for i, profile := range c.Client.Profiles {
+		if profile.Name == "" || profile.ServerAddress == "" {
+			return fmt.Errorf("client profile %d needs a name and a server_address", i+1)
+		}
+	}
+	return nil
+}
+
+// redacted replaces secrets in printed configurations.
+const redacted = "<redacted>"
+
+// Print writes the configuration as YAML, tokens and password hashes are
+// redacted.
+func (c *Config) Print(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	defer encoder.Close()
+	return encoder.Encode(c.redact())
+}
+
+// redact returns a copy without secrets, set secrets are still shown as set.
+func (c *Config) redact() *Config {
+	printed := *c
+	if printed.Server.AdminToken != "" {
+		printed.Server.AdminToken = redacted
+	}
+	if printed.Client.AdminToken != "" {
+		printed.Client.AdminToken = redacted
+	}
+	if printed.Server.Accounts != nil {
+		printed.Server.Accounts = make(map[string]string, len(c.Server.Accounts))
+		for username := range c.Server.Accounts {
+			printed.Server.Accounts[username] = redacted
+		}
+	}
+	return &printed
+}
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix starts every environment variable understood by Set, for example
+// CHAT_SERVER_LISTEN overrides server.listen.
+const EnvPrefix = "CHAT_"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Keys lists every dotted key of the configuration, in declaration order.
+func Keys() []string {
+	keys := make([]string, 0, 32)
+	var walk func(t reflect.Type, prefix string)
+	walk = func(t reflect.Type, prefix string) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			key := prefix + yamlName(field)
+			if field.Type.Kind() == reflect.Struct {
+				walk(field.Type, key+".")
+				continue
+			}
+			keys = append(keys, key)
+		}
+	}
+	walk(reflect.TypeOf(Config{}), "")
+	return keys
+}
+
+// Set assigns a value given as text to a dotted key such as "client.queue_size".
+func (c *Config) Set(key, value string) error {
+	field := reflect.ValueOf(c).Elem()
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		// string maps take one more part as the entry name, e.g. ui.keys.quit
+		if field.Kind() == reflect.Map && field.Type().Elem().Kind() == reflect.String && i == len(parts)-1 {
+			if field.IsNil() {
+				field.Set(reflect.MakeMap(field.Type()))
+			}
+			field.SetMapIndex(reflect.ValueOf(part), reflect.ValueOf(value))
+			return nil
+		}
+		if field.Kind() != reflect.Struct {
+			return fmt.Errorf("unknown config key %q", key)
+		}
+		next, ok := fieldByYamlName(field, part)
+		if !ok {
+			return fmt.Errorf("unknown config key %q", key)
+		}
+		field = next
+	}
+
+	switch {
+	case field.Type() == durationType:
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		field.SetInt(int64(duration))
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+	case field.Kind() == reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		field.SetBool(parsed)
+	case field.Kind() == reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		field.SetFloat(parsed)
+	case field.Kind() == reflect.Int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		field.SetInt(int64(parsed))
+	default:
+		return fmt.Errorf("config key %q can't be set from text", key)
+	}
+	return nil
+}
+
+// EnvName is the environment variable that overrides a key.
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// ApplyEnv overrides keys with the CHAT_* variables found in the environment.
+func (c *Config) ApplyEnv() error {
+	for _, key := range Keys() {
+		if value, ok := os.LookupEnv(EnvName(key)); ok {
+			if err := c.Set(key, value); err != nil {
+				return fmt.Errorf("%s: %w", EnvName(key), err)
+			}
+		}
+	}
+	return nil
+}
+
+// Overrides collects config values passed as command line flags. Only flags
+// present on the command line are applied, so they don't mask the file or
+// environment with their zero values.
+type Overrides struct {
+	values [][2]string
+}
+
+type keyFlag struct {
+	key       string
+	boolFlag  bool
+	overrides *Overrides
+}
+
+func (f *keyFlag) String() string   { return "" }
+func (f *keyFlag) IsBoolFlag() bool { return f.boolFlag }
+
+func (f *keyFlag) Set(value string) error {
+	f.overrides.values = append(f.overrides.values, [2]string{f.key, value})
+	return nil
+}
+
+type setFlag struct {
+	overrides *Overrides
+}
+
+func (f *setFlag) String() string { return "" }
+
+func (f *setFlag) Set(value string) error {
+	key, keyValue, found := strings.Cut(value, "=")
+	if !found {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	f.overrides.values = append(f.overrides.values, [2]string{key, keyValue})
+	return nil
+}
+
+// Flag binds a command line flag to a config key.
+type Flag struct {
+	Name  string
+	Key   string
+	Usage string
+}
+
+// BindFlags registers the given flags and a repeatable -set key=value flag
+// for every other key.
+func BindFlags(fs *flag.FlagSet, flags []Flag) *Overrides {
+	overrides := &Overrides{}
+	for _, f := range flags {
+		boolFlag := false
+		if field, ok := lookupType(f.Key); ok && field.Kind() == reflect.Bool {
+			boolFlag = true
+		}
+		fs.Var(&keyFlag{key: f.Key, boolFlag: boolFlag, overrides: overrides}, f.Name, f.Usage+" ("+f.Key+")")
+	}
+	fs.Var(&setFlag{overrides: overrides}, "set", "override any config key, e.g. -set client.queue_size=200 (repeatable)")
+	return overrides
+}
+
+// Apply writes the collected flag values into cfg.
+func (o *Overrides) Apply(cfg *Config) error {
+	for _, kv := range o.values {
+		if err := cfg.Set(kv[0], kv[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func yamlName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
+
+func fieldByYamlName(v reflect.Value, name string) (reflect.Value, bool) {
+	for i := 0; i < v.NumField(); i++ {
+		if yamlName(v.Type().Field(i)) == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func lookupType(key string) (reflect.Type, bool) {
+	t := reflect.TypeOf(Config{})
+	for _, part := range strings.Split(key, ".") {
+		if t.Kind() != reflect.Struct {
+			return nil, false
+		}
+		found := false
+		for i := 0; i < t.NumField(); i++ {
+			if yamlName(t.Field(i)) == part {
+				t, found = t.Field(i).Type, true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return t, true
+}
@@ -0,0 +1,36 @@
+package config
+
+import "os"
+
+// ConfigEnv names the config file when -config is not given.
+const ConfigEnv = "CHAT_CONFIG"
+
+// Load builds the effective configuration: defaults, the config file, the
+// environment and finally the flag overrides.
+func Load(path string, overrides *Overrides) (Config, error) {
+	cfg := Default()
+
+	required := path != ""
+	if !required {
+		if fromEnv, ok := os.LookupEnv(ConfigEnv); ok {
+			path, required = fromEnv, true
+		} else {
+			path = DefaultPath()
+		}
+	}
+	if path != "" {
+		if err := LoadFile(&cfg, path, required); err != nil {
+			return cfg, err
+		}
+	}
+
+	if err := cfg.ApplyEnv(); err != nil {
+		return cfg, err
+	}
+	if overrides != nil {
+		if err := overrides.Apply(&cfg); err != nil {
+			return cfg, err
+		}
+	}
+	return cfg, cfg.Validate()
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+)
+
+// profilesFile is the layout of client.profiles_file, the profiles saved by
+// the login page. They are kept apart from the config file, which is only
+// ever read.
+type profilesFile struct {
+	Profiles []ServerProfile `yaml:"profiles"`
+}
+
+// DefaultProfilesPath is the profiles file next to the default config file.
+func DefaultProfilesPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "chat", "profiles.yaml")
+}
+
+// LoadProfiles reads the saved profiles, a missing file has none.
+func LoadProfiles(path string) ([]ServerProfile, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read profiles: %w", err)
+	}
+	var file profilesFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("parse profiles %s: %w", path, err)
+	}
+	for i, profile := range file.Profiles {
+		if profile.Name == "" || profile.ServerAddress == "" {
+			return nil, fmt.Errorf("saved profile %d in %s needs a name and a server_address", i+1, path)
+		}
+	}
+	return file.Profiles, nil
+}
+
+// SaveProfiles replaces the profiles file, a crash while writing leaves the
+// old one.
+func SaveProfiles(path string, profiles []ServerProfile) error {
+	if path == "" {
+		return errors.New("client.profiles_file is not set")
+	}
+	content, err := yaml.Marshal(profilesFile{Profiles: profiles})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("write profiles: %w", err)
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("write profiles: %w", err)
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(content); err != nil {
+		temp.Close()
+		return fmt.Errorf("write profiles: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("write profiles: %w", err)
+	}
+	return os.Rename(temp.Name(), path)
+}
@@ -7,8 +7,10 @@ require (
 	github.com/golang/protobuf v1.5.3
 	github.com/google/uuid v1.3.1
 	github.com/rivo/tview v0.0.0-20230916092115-0ad06c2ea3dd
+	golang.org/x/crypto v0.11.0
 	google.golang.org/grpc v1.58.1
 	google.golang.org/protobuf v1.31.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,93 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Options describe where log records go and how they look.
+type Options struct {
+	// Destination is "stdout", "stderr", "discard" or a file path (appended).
+	Destination string
+	// Level is one of debug, info, warn, error.
+	Level string
+	// Format is "text" or "json".
+	Format string
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// New builds a logger for the given options. The returned closer releases the
+// log file, if one was opened.
+func New(opts Options) (*slog.Logger, io.Closer, error) {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		out    io.Writer
+		closer io.Closer = nopCloser{}
+	)
+	switch opts.Destination {
+	case "", "stdout":
+		out = os.Stdout
+	case "stderr":
+		out = os.Stderr
+	case "discard":
+		out = io.Discard
+	default:
+		file, err := os.OpenFile(opts.Destination, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open log destination: %w", err)
+		}
+		out, closer = file, file
+	}
+
+	handlerOptions := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(opts.Format) {
+	case "", "text":
+		handler = slog.NewTextHandler(out, handlerOptions)
+	case "json":
+		handler = slog.NewJSONHandler(out, handlerOptions)
+	default:
+		closer.Close()
+		return nil, nil, fmt.Errorf("unknown log format %q", opts.Format)
+	}
+
+	return slog.New(handler), closer, nil
+}
+
+// ParseLevel converts a level name into a slog.Level.
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if name == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return level, fmt.Errorf("unknown log level %q", name)
+	}
+	return level, nil
+}
+
+type contextKey struct{}
+
+// WithLogger stores a request scoped logger in the context.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the request scoped logger, or the default one.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
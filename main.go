@@ -1,100 +1,222 @@
 package main
 
 import (
+	"bufio"
 	"chat/client"
+	"chat/config"
+	"chat/logging"
 	"chat/protos"
 	"chat/server"
+	"errors"
 	"flag"
 	"fmt"
 	"github.com/rivo/tview"
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"io"
 	"log"
+	"log/slog"
 	"net"
 	"os"
-	"time"
+	"os/signal"
+	"strings"
+	"syscall"
 )
 
+var configFlags = []config.Flag{
+	{Name: "listen", Key: "server.listen", Usage: "server listen address"},
+	{Name: "reflection", Key: "server.reflection", Usage: "register the grpc reflection service"},
+	{Name: "address", Key: "client.server_address", Usage: "server address used by the client"},
+	{Name: "theme", Key: "ui.theme", Usage: "terminal colour theme"},
+	{Name: "dnd", Key: "ui.alerts.do_not_disturb", Usage: "start with alerts silenced"},
+	{Name: "log", Key: "log.destination", Usage: "log destination: stdout, stderr, discard or a file path (default stdout for the server, chat-client.log for the client)"},
+	{Name: "log-level", Key: "log.level", Usage: "log level: debug, info, warn, error"},
+	{Name: "log-format", Key: "log.format", Usage: "log format: text or json"},
+}
+
 func main() {
 
 	var serverMode bool
+	var configPath string
 	flag.BoolVar(&serverMode, "server", false, "start a server")
+	flag.StringVar(&configPath, "config", "", "config file (default $"+config.ConfigEnv+" or "+config.DefaultPath()+")")
+	overrides := config.BindFlags(flag.CommandLine, configFlags)
 	flag.Parse()
 
-	// logger
-	timestamp := fmt.Sprintf("%d", time.Now().Unix())
-	fileName := fmt.Sprintf("logfile-%s.txt", timestamp[len(timestamp)-5:])
-	logFile, err := os.Create(fileName)
+	cfg, err := config.Load(configPath, overrides)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// subcommands
+	if args := flag.Args(); len(args) > 0 {
+		if err := runCommand(&cfg, args); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		return
+	}
+
+	// logger, the terminal client can't share stdout with the ui
+	logOptions := logging.Options{
+		Destination: cfg.Log.Destination,
+		Level:       cfg.Log.Level,
+		Format:      cfg.Log.Format,
+	}
+	if logOptions.Destination == "" && !serverMode {
+		logOptions.Destination = "chat-client.log"
+	}
+	logger, logCloser, err := logging.New(logOptions)
 	if err != nil {
 		log.Fatal(err)
 	}
-	log.SetOutput(logFile)
-	defer logFile.Close()
+	slog.SetDefault(logger)
+	defer logCloser.Close()
 
 	if serverMode {
-		serverStart()
+		err = serverStart(cfg.Server)
 	} else {
-		clientStart()
+		err = clientStart(cfg)
+	}
+	if err != nil {
+		slog.Error("chat stopped", "err", err)
+		logCloser.Close()
+		os.Exit(1)
+	}
+}
 
+func runCommand(cfg *config.Config, args []string) error {
+	switch {
+	case len(args) == 2 && args[0] == "config" && args[1] == "print":
+		return cfg.Print(os.Stdout)
+	case args[0] == "admin":
+		return adminCommand(cfg.Client, args[1:])
+	case len(args) == 1 && args[0] == "hash-password":
+		return hashPasswordCommand(os.Stdin, os.Stdout)
+	default:
+		return fmt.Errorf("unknown command %q, available: config print, admin, hash-password", args)
 	}
 }
 
-func serverStart() {
-	log.SetOutput(os.Stdout)
+// hashPasswordCommand reads a password line and prints its server.accounts
+// entry.
+func hashPasswordCommand(in io.Reader, out io.Writer) error {
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return errors.New("usage: echo <password> | chat hash-password")
+	}
+	entry, err := server.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, entry)
+	return nil
+}
 
-	implementedGrpc := server.NewGrpcImplementation()
+func serverStart(cfg config.ServerConfig) error {
+	implementedGrpc, err := server.NewGrpcImplementation(cfg)
+	if err != nil {
+		return err
+	}
+	defer implementedGrpc.Close()
+	rateLimiter := server.NewRateLimiter(cfg.RateLimit)
 
-	grpcServer := grpc.NewServer(
-		grpc.UnaryInterceptor(implementedGrpc.UnaryServerInterceptor),
+	options := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(implementedGrpc.UnaryServerInterceptor, rateLimiter.UnaryServerInterceptor),
 		grpc.StreamInterceptor(implementedGrpc.StreamServerInterceptor),
-	)
+	}
+	if cfg.TLS.CertFile != "" {
+		creds, err := config.ServerCredentials(cfg.TLS)
+		if err != nil {
+			return err
+		}
+		options = append(options, grpc.Creds(creds))
+	}
+	grpcServer := grpc.NewServer(options...)
 
 	protos.RegisterRegisterUserServer(grpcServer, implementedGrpc)
+	protos.RegisterAdminServer(grpcServer, server.NewAdminImplementation(implementedGrpc))
 
-	net, err := net.Listen("tcp", ":8898")
-	if err != nil {
-		log.Fatal(err)
+	healthServer := server.NewHealthServer(implementedGrpc.AdminEnabled())
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	if cfg.Reflection {
+		reflection.Register(grpcServer)
+		slog.Info("grpc reflection enabled")
 	}
-	log.Println("Grpc server started!")
-	err = grpcServer.Serve(net)
+
+	net, err := net.Listen("tcp", cfg.Listen)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
+	// report not serving before the listener goes away
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-stop
+		slog.Info("shutting down")
+		healthServer.Shutdown()
+		grpcServer.Stop()
+	}()
+
+	slog.Info("grpc server started", "address", net.Addr().String(), "tls", cfg.TLS.CertFile != "")
+	return grpcServer.Serve(net)
 }
 
-func clientStart() {
-	database := client.NewInMemoryChatDatabase()
-	appExit := make(chan bool, 1)
-	service := client.NewChatServiceImplementation(appExit, database)
-
-	conn, err := grpc.Dial(":8898", grpc.WithInsecure(),
-		grpc.WithUnaryInterceptor(service.UnaryClientInterceptor),
-		grpc.WithStreamInterceptor(service.StreamClientInterceptor),
-	)
+func clientStart(cfg config.Config) error {
+	database, err := client.NewLocalDatabase(cfg.Client.Storage)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := database.Close(); err != nil {
+			slog.Warn("client store not saved", "err", err)
+		}
+	}()
+	keymap, err := client.NewKeymap(cfg.UI.Keys)
 	if err != nil {
-		log.Fatalf("did not connect: %s", err)
+		return err
 	}
-	defer conn.Close()
+	themes, err := client.LoadThemes(cfg.UI.ThemesFile, cfg.UI.Theme)
+	if err != nil {
+		return fmt.Errorf("ui themes: %w", err)
+	}
+	clock, err := client.NewClock(cfg.UI.Time)
+	if err != nil {
+		return err
+	}
+	profiles, err := client.NewProfiles(cfg.Client)
+	if err != nil {
+		return err
+	}
+	alerts := client.NewAlerts(cfg.UI.Alerts)
+	defer alerts.Close()
+	appExit := make(chan bool, 1)
+	service := client.NewChatServiceImplementation(appExit, database, cfg.Client)
 
-	service.InitGrpcClient(conn)
+	// the login page may connect to another server
+	if err := service.Connect(cfg.Client.ServerAddress, cfg.Client.TLS); err != nil {
+		return err
+	}
+	defer service.Close()
 
 	var terminalApplication *tview.Application
 
 	activeBoxManager := client.ActiveBoxManager{}
-	terminalApplication = client.NewTerminalApplication(service, activeBoxManager, appExit)
+	terminalApplication = client.NewTerminalApplication(service, activeBoxManager, appExit, keymap, themes, alerts, clock, profiles)
 	err = terminalApplication.Run()
 	if err != nil {
-		return
+		return err
 	}
-	service.Unregister()
-	fmt.Println("Bye!")
-}
-
-func serverExists() bool {
-	conn, err := net.Dial("tcp", "localhost:8898")
-	if err != nil {
-		return false
+	if err := service.Unregister(); err != nil {
+		slog.Warn("deregistration failed", "err", err)
 	}
-	defer conn.Close()
-	return true
+	fmt.Println("Bye!")
+	return nil
 }
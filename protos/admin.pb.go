@@ -0,0 +1,649 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: admin.proto
+
+package protos
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type Session struct {
+	User       *User                `protobuf:"bytes,1,opt,name=User,proto3" json:"User,omitempty"`
+	Registered *timestamp.Timestamp `protobuf:"bytes,2,opt,name=Registered,proto3" json:"Registered,omitempty"`
+	// zero when the update stream is not open
+	StreamStarted        *timestamp.Timestamp `protobuf:"bytes,3,opt,name=StreamStarted,proto3" json:"StreamStarted,omitempty"`
+	StreamAgeSeconds     int64                `protobuf:"varint,4,opt,name=StreamAgeSeconds,proto3" json:"StreamAgeSeconds,omitempty"`
+	QueueDepth           int32                `protobuf:"varint,5,opt,name=QueueDepth,proto3" json:"QueueDepth,omitempty"`
+	QueueCapacity        int32                `protobuf:"varint,6,opt,name=QueueCapacity,proto3" json:"QueueCapacity,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Session) Reset()         { *m = Session{} }
+func (m *Session) String() string { return proto.CompactTextString(m) }
+func (*Session) ProtoMessage()    {}
+func (*Session) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{0}
+}
+
+func (m *Session) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Session.Unmarshal(m, b)
+}
+func (m *Session) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Session.Marshal(b, m, deterministic)
+}
+func (m *Session) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Session.Merge(m, src)
+}
+func (m *Session) XXX_Size() int {
+	return xxx_messageInfo_Session.Size(m)
+}
+func (m *Session) XXX_DiscardUnknown() {
+	xxx_messageInfo_Session.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Session proto.InternalMessageInfo
+
+func (m *Session) GetUser() *User {
+	if m != nil {
+		return m.User
+	}
+	return nil
+}
+
+func (m *Session) GetRegistered() *timestamp.Timestamp {
+	if m != nil {
+		return m.Registered
+	}
+	return nil
+}
+
+func (m *Session) GetStreamStarted() *timestamp.Timestamp {
+	if m != nil {
+		return m.StreamStarted
+	}
+	return nil
+}
+
+func (m *Session) GetStreamAgeSeconds() int64 {
+	if m != nil {
+		return m.StreamAgeSeconds
+	}
+	return 0
+}
+
+func (m *Session) GetQueueDepth() int32 {
+	if m != nil {
+		return m.QueueDepth
+	}
+	return 0
+}
+
+func (m *Session) GetQueueCapacity() int32 {
+	if m != nil {
+		return m.QueueCapacity
+	}
+	return 0
+}
+
+type SessionList struct {
+	Sessions             []*Session `protobuf:"bytes,1,rep,name=Sessions,proto3" json:"Sessions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *SessionList) Reset()         { *m = SessionList{} }
+func (m *SessionList) String() string { return proto.CompactTextString(m) }
+func (*SessionList) ProtoMessage()    {}
+func (*SessionList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{1}
+}
+
+func (m *SessionList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SessionList.Unmarshal(m, b)
+}
+func (m *SessionList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SessionList.Marshal(b, m, deterministic)
+}
+func (m *SessionList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SessionList.Merge(m, src)
+}
+func (m *SessionList) XXX_Size() int {
+	return xxx_messageInfo_SessionList.Size(m)
+}
+func (m *SessionList) XXX_DiscardUnknown() {
+	xxx_messageInfo_SessionList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SessionList proto.InternalMessageInfo
+
+func (m *SessionList) GetSessions() []*Session {
+	if m != nil {
+		return m.Sessions
+	}
+	return nil
+}
+
+type KickRequest struct {
+	UserId               string   `protobuf:"bytes,1,opt,name=UserId,proto3" json:"UserId,omitempty"`
+	Reason               string   `protobuf:"bytes,2,opt,name=Reason,proto3" json:"Reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *KickRequest) Reset()         { *m = KickRequest{} }
+func (m *KickRequest) String() string { return proto.CompactTextString(m) }
+func (*KickRequest) ProtoMessage()    {}
+func (*KickRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{2}
+}
+
+func (m *KickRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KickRequest.Unmarshal(m, b)
+}
+func (m *KickRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KickRequest.Marshal(b, m, deterministic)
+}
+func (m *KickRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KickRequest.Merge(m, src)
+}
+func (m *KickRequest) XXX_Size() int {
+	return xxx_messageInfo_KickRequest.Size(m)
+}
+func (m *KickRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_KickRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KickRequest proto.InternalMessageInfo
+
+func (m *KickRequest) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *KickRequest) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type BanRequest struct {
+	Username             string   `protobuf:"bytes,1,opt,name=Username,proto3" json:"Username,omitempty"`
+	Reason               string   `protobuf:"bytes,2,opt,name=Reason,proto3" json:"Reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BanRequest) Reset()         { *m = BanRequest{} }
+func (m *BanRequest) String() string { return proto.CompactTextString(m) }
+func (*BanRequest) ProtoMessage()    {}
+func (*BanRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{3}
+}
+
+func (m *BanRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BanRequest.Unmarshal(m, b)
+}
+func (m *BanRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BanRequest.Marshal(b, m, deterministic)
+}
+func (m *BanRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BanRequest.Merge(m, src)
+}
+func (m *BanRequest) XXX_Size() int {
+	return xxx_messageInfo_BanRequest.Size(m)
+}
+func (m *BanRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BanRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BanRequest proto.InternalMessageInfo
+
+func (m *BanRequest) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *BanRequest) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type AnnouncementRequest struct {
+	Text                 string   `protobuf:"bytes,1,opt,name=Text,proto3" json:"Text,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AnnouncementRequest) Reset()         { *m = AnnouncementRequest{} }
+func (m *AnnouncementRequest) String() string { return proto.CompactTextString(m) }
+func (*AnnouncementRequest) ProtoMessage()    {}
+func (*AnnouncementRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{4}
+}
+
+func (m *AnnouncementRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AnnouncementRequest.Unmarshal(m, b)
+}
+func (m *AnnouncementRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AnnouncementRequest.Marshal(b, m, deterministic)
+}
+func (m *AnnouncementRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AnnouncementRequest.Merge(m, src)
+}
+func (m *AnnouncementRequest) XXX_Size() int {
+	return xxx_messageInfo_AnnouncementRequest.Size(m)
+}
+func (m *AnnouncementRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AnnouncementRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AnnouncementRequest proto.InternalMessageInfo
+
+func (m *AnnouncementRequest) GetText() string {
+	if m != nil {
+		return m.Text
+	}
+	return ""
+}
+
+type ServerStats struct {
+	Started              *timestamp.Timestamp `protobuf:"bytes,1,opt,name=Started,proto3" json:"Started,omitempty"`
+	UptimeSeconds        int64                `protobuf:"varint,2,opt,name=UptimeSeconds,proto3" json:"UptimeSeconds,omitempty"`
+	OnlineUsers          int32                `protobuf:"varint,3,opt,name=OnlineUsers,proto3" json:"OnlineUsers,omitempty"`
+	OpenStreams          int32                `protobuf:"varint,4,opt,name=OpenStreams,proto3" json:"OpenStreams,omitempty"`
+	Registrations        int64                `protobuf:"varint,5,opt,name=Registrations,proto3" json:"Registrations,omitempty"`
+	MessagesForwarded    int64                `protobuf:"varint,6,opt,name=MessagesForwarded,proto3" json:"MessagesForwarded,omitempty"`
+	MessagesDropped      int64                `protobuf:"varint,7,opt,name=MessagesDropped,proto3" json:"MessagesDropped,omitempty"`
+	BannedUsernames      int32                `protobuf:"varint,8,opt,name=BannedUsernames,proto3" json:"BannedUsernames,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *ServerStats) Reset()         { *m = ServerStats{} }
+func (m *ServerStats) String() string { return proto.CompactTextString(m) }
+func (*ServerStats) ProtoMessage()    {}
+func (*ServerStats) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{5}
+}
+
+func (m *ServerStats) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServerStats.Unmarshal(m, b)
+}
+func (m *ServerStats) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServerStats.Marshal(b, m, deterministic)
+}
+func (m *ServerStats) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServerStats.Merge(m, src)
+}
+func (m *ServerStats) XXX_Size() int {
+	return xxx_messageInfo_ServerStats.Size(m)
+}
+func (m *ServerStats) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServerStats.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServerStats proto.InternalMessageInfo
+
+func (m *ServerStats) GetStarted() *timestamp.Timestamp {
+	if m != nil {
+		return m.Started
+	}
+	return nil
+}
+
+func (m *ServerStats) GetUptimeSeconds() int64 {
+	if m != nil {
+		return m.UptimeSeconds
+	}
+	return 0
+}
+
+func (m *ServerStats) GetOnlineUsers() int32 {
+	if m != nil {
+		return m.OnlineUsers
+	}
+	return 0
+}
+
+func (m *ServerStats) GetOpenStreams() int32 {
+	if m != nil {
+		return m.OpenStreams
+	}
+	return 0
+}
+
+func (m *ServerStats) GetRegistrations() int64 {
+	if m != nil {
+		return m.Registrations
+	}
+	return 0
+}
+
+func (m *ServerStats) GetMessagesForwarded() int64 {
+	if m != nil {
+		return m.MessagesForwarded
+	}
+	return 0
+}
+
+func (m *ServerStats) GetMessagesDropped() int64 {
+	if m != nil {
+		return m.MessagesDropped
+	}
+	return 0
+}
+
+func (m *ServerStats) GetBannedUsernames() int32 {
+	if m != nil {
+		return m.BannedUsernames
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*Session)(nil), "Session")
+	proto.RegisterType((*SessionList)(nil), "SessionList")
+	proto.RegisterType((*KickRequest)(nil), "KickRequest")
+	proto.RegisterType((*BanRequest)(nil), "BanRequest")
+	proto.RegisterType((*AnnouncementRequest)(nil), "AnnouncementRequest")
+	proto.RegisterType((*ServerStats)(nil), "ServerStats")
+}
+
+func init() {
+	proto.RegisterFile("admin.proto", fileDescriptor_73a7fc70dcc2027c)
+}
+
+var fileDescriptor_73a7fc70dcc2027c = []byte{
+	// 550 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x93, 0x5d, 0x6f, 0xd3, 0x3e,
+	0x14, 0xc6, 0x95, 0xb6, 0x69, 0xb3, 0x93, 0xee, 0xff, 0x07, 0xf3, 0xa2, 0xd0, 0x0b, 0x88, 0xa2,
+	0x0a, 0x15, 0x84, 0x3c, 0xa9, 0x83, 0x1b, 0x24, 0xa4, 0xb5, 0x0c, 0x10, 0x02, 0x84, 0x70, 0xb7,
+	0x1b, 0xee, 0xbc, 0xe4, 0xd0, 0x45, 0x2c, 0x76, 0xb0, 0x5d, 0x60, 0xdf, 0x83, 0x4f, 0x86, 0xc4,
+	0xf7, 0x41, 0x76, 0x5e, 0x48, 0x37, 0xd0, 0xae, 0x92, 0xf3, 0xcb, 0x73, 0x7c, 0x92, 0xf3, 0x3c,
+	0x81, 0x90, 0x67, 0x45, 0x2e, 0x68, 0xa9, 0xa4, 0x91, 0x93, 0x7b, 0x6b, 0x29, 0xd7, 0x67, 0xb8,
+	0xe7, 0xaa, 0x93, 0xcd, 0xa7, 0x3d, 0x93, 0x17, 0xa8, 0x0d, 0x2f, 0xca, 0x5a, 0x00, 0xe9, 0x29,
+	0x37, 0xd5, 0x7d, 0xf2, 0xa3, 0x07, 0xa3, 0x15, 0x6a, 0x9d, 0x4b, 0x41, 0xee, 0xc0, 0xe0, 0x58,
+	0xa3, 0x8a, 0xbc, 0xd8, 0x9b, 0x85, 0x73, 0x9f, 0xda, 0x82, 0x39, 0x44, 0x9e, 0x02, 0x30, 0x5c,
+	0xe7, 0xda, 0xa0, 0xc2, 0x2c, 0xea, 0x39, 0xc1, 0x84, 0x56, 0x83, 0x68, 0x33, 0x88, 0x1e, 0x35,
+	0x83, 0x58, 0x47, 0x4d, 0x0e, 0x60, 0x77, 0x65, 0x14, 0xf2, 0x62, 0x65, 0xb8, 0x32, 0x98, 0x45,
+	0xfd, 0x2b, 0xdb, 0xb7, 0x1b, 0xc8, 0x43, 0xb8, 0x56, 0x81, 0xc5, 0x1a, 0x57, 0x98, 0x4a, 0x91,
+	0xe9, 0x68, 0x10, 0x7b, 0xb3, 0x3e, 0xbb, 0xc4, 0xc9, 0x5d, 0x80, 0x0f, 0x1b, 0xdc, 0xe0, 0x21,
+	0x96, 0xe6, 0x34, 0xf2, 0x63, 0x6f, 0xe6, 0xb3, 0x0e, 0x21, 0x53, 0xd8, 0x75, 0xd5, 0x73, 0x5e,
+	0xf2, 0x34, 0x37, 0xe7, 0xd1, 0xd0, 0x49, 0xb6, 0x61, 0xb2, 0x0f, 0x61, 0xbd, 0x95, 0xb7, 0xb9,
+	0x36, 0x64, 0x0a, 0x41, 0x5d, 0xea, 0xc8, 0x8b, 0xfb, 0xb3, 0x70, 0x1e, 0xd0, 0x1a, 0xb0, 0xf6,
+	0x49, 0xf2, 0x0c, 0xc2, 0x37, 0x79, 0xfa, 0x99, 0xe1, 0x97, 0x0d, 0x6a, 0x43, 0x6e, 0xc3, 0xd0,
+	0xee, 0xee, 0x75, 0xe6, 0x16, 0xba, 0xc3, 0xea, 0xca, 0x72, 0x86, 0x5c, 0x4b, 0xe1, 0xf6, 0xb8,
+	0xc3, 0xea, 0x2a, 0x39, 0x00, 0x58, 0x72, 0xd1, 0x74, 0x4f, 0x20, 0xb0, 0x7a, 0xc1, 0x0b, 0xac,
+	0xfb, 0xdb, 0xfa, 0x9f, 0x27, 0x3c, 0x80, 0x1b, 0x0b, 0x21, 0xe4, 0x46, 0xa4, 0x58, 0xa0, 0x30,
+	0xcd, 0x51, 0x04, 0x06, 0x47, 0xf8, 0xdd, 0xd4, 0xc7, 0xb8, 0xfb, 0xe4, 0x57, 0xcf, 0x7e, 0xa1,
+	0xfa, 0x8a, 0x6a, 0x65, 0xb8, 0xd1, 0xe4, 0x31, 0x8c, 0x1a, 0x7b, 0xbc, 0x2b, 0xed, 0x69, 0xa4,
+	0x76, 0x99, 0xc7, 0xa5, 0x8d, 0x57, 0xe3, 0x4a, 0xcf, 0xb9, 0xb2, 0x0d, 0x49, 0x0c, 0xe1, 0x7b,
+	0x71, 0x96, 0x0b, 0xb4, 0x1f, 0xa0, 0x9d, 0xfd, 0x3e, 0xeb, 0x22, 0xa7, 0x28, 0x51, 0x54, 0x66,
+	0x56, 0xde, 0xfa, 0xac, 0x8b, 0xec, 0xa4, 0x2a, 0x52, 0x8a, 0x1b, 0x67, 0x83, 0x5f, 0x4d, 0xda,
+	0x82, 0xe4, 0x11, 0x5c, 0x7f, 0x87, 0x5a, 0xf3, 0x35, 0xea, 0x97, 0x52, 0x7d, 0xe3, 0x2a, 0xc3,
+	0xcc, 0x19, 0xdc, 0x67, 0x97, 0x1f, 0x90, 0x19, 0xfc, 0xdf, 0xc0, 0x43, 0x25, 0xcb, 0x12, 0xb3,
+	0x68, 0xe4, 0xb4, 0x17, 0xb1, 0x55, 0x2e, 0xb9, 0x10, 0x98, 0x35, 0x16, 0xe8, 0x28, 0x70, 0xef,
+	0x78, 0x11, 0xcf, 0x7f, 0x7a, 0xe0, 0x2f, 0xec, 0xcf, 0x48, 0xa6, 0x30, 0xb6, 0xd9, 0x69, 0xd2,
+	0x41, 0x86, 0xf4, 0x45, 0x51, 0x9a, 0xf3, 0xc9, 0x98, 0x76, 0x93, 0x15, 0x43, 0x60, 0x33, 0xe3,
+	0x7e, 0xb2, 0x31, 0xed, 0xc4, 0x67, 0x52, 0xeb, 0x49, 0x02, 0xe1, 0x92, 0x8b, 0xd6, 0xfb, 0x90,
+	0xfe, 0x09, 0x49, 0xab, 0x79, 0x02, 0xb7, 0x96, 0x4a, 0xf2, 0x2c, 0xe5, 0xda, 0x74, 0x13, 0x40,
+	0x6e, 0xd2, 0xbf, 0x04, 0xa2, 0x6d, 0xbb, 0x0f, 0xff, 0xbd, 0x42, 0xd3, 0x8d, 0x41, 0xf7, 0x25,
+	0x5b, 0xba, 0x0c, 0x3e, 0x0e, 0x5d, 0x0a, 0xf4, 0x49, 0x75, 0xdd, 0xff, 0x3d, 0x00, 0x61, 0xf7,
+	0xe5, 0x7f, 0x71, 0x04, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// AdminClient is the client API for Admin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AdminClient interface {
+	ListSessions(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SessionList, error)
+	KickUser(ctx context.Context, in *KickRequest, opts ...grpc.CallOption) (*Empty, error)
+	BanUsername(ctx context.Context, in *BanRequest, opts ...grpc.CallOption) (*Empty, error)
+	BroadcastAnnouncement(ctx context.Context, in *AnnouncementRequest, opts ...grpc.CallOption) (*Empty, error)
+	GetServerStats(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ServerStats, error)
+}
+
+type adminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminClient(cc grpc.ClientConnInterface) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) ListSessions(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SessionList, error) {
+	out := new(SessionList)
+	err := c.cc.Invoke(ctx, "/Admin/ListSessions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) KickUser(ctx context.Context, in *KickRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/Admin/KickUser", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) BanUsername(ctx context.Context, in *BanRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/Admin/BanUsername", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) BroadcastAnnouncement(ctx context.Context, in *AnnouncementRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/Admin/BroadcastAnnouncement", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetServerStats(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ServerStats, error) {
+	out := new(ServerStats)
+	err := c.cc.Invoke(ctx, "/Admin/GetServerStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServer is the server API for Admin service.
+type AdminServer interface {
+	ListSessions(context.Context, *Empty) (*SessionList, error)
+	KickUser(context.Context, *KickRequest) (*Empty, error)
+	BanUsername(context.Context, *BanRequest) (*Empty, error)
+	BroadcastAnnouncement(context.Context, *AnnouncementRequest) (*Empty, error)
+	GetServerStats(context.Context, *Empty) (*ServerStats, error)
+}
+
+// UnimplementedAdminServer can be embedded to have forward compatible implementations.
+type UnimplementedAdminServer struct {
+}
+
+func (*UnimplementedAdminServer) ListSessions(ctx context.Context, req *Empty) (*SessionList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSessions not implemented")
+}
+func (*UnimplementedAdminServer) KickUser(ctx context.Context, req *KickRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method KickUser not implemented")
+}
+func (*UnimplementedAdminServer) BanUsername(ctx context.Context, req *BanRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BanUsername not implemented")
+}
+func (*UnimplementedAdminServer) BroadcastAnnouncement(ctx context.Context, req *AnnouncementRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BroadcastAnnouncement not implemented")
+}
+func (*UnimplementedAdminServer) GetServerStats(ctx context.Context, req *Empty) (*ServerStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetServerStats not implemented")
+}
+
+func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
+	s.RegisterService(&_Admin_serviceDesc, srv)
+}
+
+func _Admin_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Admin/ListSessions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListSessions(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_KickUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KickRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).KickUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Admin/KickUser",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).KickUser(ctx, req.(*KickRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_BanUsername_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).BanUsername(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Admin/BanUsername",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).BanUsername(ctx, req.(*BanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_BroadcastAnnouncement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnnouncementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).BroadcastAnnouncement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Admin/BroadcastAnnouncement",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).BroadcastAnnouncement(ctx, req.(*AnnouncementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetServerStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetServerStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Admin/GetServerStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetServerStats(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Admin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListSessions",
+			Handler:    _Admin_ListSessions_Handler,
+		},
+		{
+			MethodName: "KickUser",
+			Handler:    _Admin_KickUser_Handler,
+		},
+		{
+			MethodName: "BanUsername",
+			Handler:    _Admin_BanUsername_Handler,
+		},
+		{
+			MethodName: "BroadcastAnnouncement",
+			Handler:    _Admin_BroadcastAnnouncement_Handler,
+		},
+		{
+			MethodName: "GetServerStats",
+			Handler:    _Admin_GetServerStats_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "admin.proto",
+}
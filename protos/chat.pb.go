@@ -96,7 +96,9 @@ func (m *UserList) GetUsers() []*User {
 }
 
 type RegisterRequest struct {
-	Username             string   `protobuf:"bytes,1,opt,name=Username,proto3" json:"Username,omitempty"`
+	Username string `protobuf:"bytes,1,opt,name=Username,proto3" json:"Username,omitempty"`
+	// checked for usernames that have a server account
+	Password             string   `protobuf:"bytes,2,opt,name=Password,proto3" json:"Password,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -134,9 +136,19 @@ func (m *RegisterRequest) GetUsername() string {
 	return ""
 }
 
+func (m *RegisterRequest) GetPassword() string {
+	if m != nil {
+		return m.Password
+	}
+	return ""
+}
+
 type User struct {
-	Id                   string   `protobuf:"bytes,1,opt,name=Id,proto3" json:"Id,omitempty"`
-	Username             string   `protobuf:"bytes,2,opt,name=Username,proto3" json:"Username,omitempty"`
+	Id       string `protobuf:"bytes,1,opt,name=Id,proto3" json:"Id,omitempty"`
+	Username string `protobuf:"bytes,2,opt,name=Username,proto3" json:"Username,omitempty"`
+	Status   string `protobuf:"bytes,3,opt,name=Status,proto3" json:"Status,omitempty"`
+	// shown instead of the username when set, it doesn't have to be unique
+	DisplayName          string   `protobuf:"bytes,4,opt,name=DisplayName,proto3" json:"DisplayName,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -181,6 +193,107 @@ func (m *User) GetUsername() string {
 	return ""
 }
 
+func (m *User) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *User) GetDisplayName() string {
+	if m != nil {
+		return m.DisplayName
+	}
+	return ""
+}
+
+type StatusRequest struct {
+	Status               string   `protobuf:"bytes,1,opt,name=Status,proto3" json:"Status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StatusRequest) Reset()         { *m = StatusRequest{} }
+func (m *StatusRequest) String() string { return proto.CompactTextString(m) }
+func (*StatusRequest) ProtoMessage()    {}
+func (*StatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8c585a45e2093e54, []int{4}
+}
+
+func (m *StatusRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StatusRequest.Unmarshal(m, b)
+}
+func (m *StatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StatusRequest.Marshal(b, m, deterministic)
+}
+func (m *StatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StatusRequest.Merge(m, src)
+}
+func (m *StatusRequest) XXX_Size() int {
+	return xxx_messageInfo_StatusRequest.Size(m)
+}
+func (m *StatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StatusRequest proto.InternalMessageInfo
+
+func (m *StatusRequest) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+type NicknameRequest struct {
+	Username string `protobuf:"bytes,1,opt,name=Username,proto3" json:"Username,omitempty"`
+	// empty clears the display name
+	DisplayName          string   `protobuf:"bytes,2,opt,name=DisplayName,proto3" json:"DisplayName,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *NicknameRequest) Reset()         { *m = NicknameRequest{} }
+func (m *NicknameRequest) String() string { return proto.CompactTextString(m) }
+func (*NicknameRequest) ProtoMessage()    {}
+func (*NicknameRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8c585a45e2093e54, []int{5}
+}
+
+func (m *NicknameRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NicknameRequest.Unmarshal(m, b)
+}
+func (m *NicknameRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NicknameRequest.Marshal(b, m, deterministic)
+}
+func (m *NicknameRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NicknameRequest.Merge(m, src)
+}
+func (m *NicknameRequest) XXX_Size() int {
+	return xxx_messageInfo_NicknameRequest.Size(m)
+}
+func (m *NicknameRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_NicknameRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NicknameRequest proto.InternalMessageInfo
+
+func (m *NicknameRequest) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *NicknameRequest) GetDisplayName() string {
+	if m != nil {
+		return m.DisplayName
+	}
+	return ""
+}
+
 type NewMessage struct {
 	ReceiverId           string   `protobuf:"bytes,1,opt,name=ReceiverId,proto3" json:"ReceiverId,omitempty"`
 	Message              string   `protobuf:"bytes,2,opt,name=Message,proto3" json:"Message,omitempty"`
@@ -193,7 +306,7 @@ func (m *NewMessage) Reset()         { *m = NewMessage{} }
 func (m *NewMessage) String() string { return proto.CompactTextString(m) }
 func (*NewMessage) ProtoMessage()    {}
 func (*NewMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8c585a45e2093e54, []int{4}
+	return fileDescriptor_8c585a45e2093e54, []int{6}
 }
 
 func (m *NewMessage) XXX_Unmarshal(b []byte) error {
@@ -228,6 +341,45 @@ func (m *NewMessage) GetMessage() string {
 	return ""
 }
 
+type BlockRequest struct {
+	UserId               string   `protobuf:"bytes,1,opt,name=UserId,proto3" json:"UserId,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BlockRequest) Reset()         { *m = BlockRequest{} }
+func (m *BlockRequest) String() string { return proto.CompactTextString(m) }
+func (*BlockRequest) ProtoMessage()    {}
+func (*BlockRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8c585a45e2093e54, []int{7}
+}
+
+func (m *BlockRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BlockRequest.Unmarshal(m, b)
+}
+func (m *BlockRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BlockRequest.Marshal(b, m, deterministic)
+}
+func (m *BlockRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlockRequest.Merge(m, src)
+}
+func (m *BlockRequest) XXX_Size() int {
+	return xxx_messageInfo_BlockRequest.Size(m)
+}
+func (m *BlockRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlockRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlockRequest proto.InternalMessageInfo
+
+func (m *BlockRequest) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
 type DirectMessage struct {
 	SenderId             string               `protobuf:"bytes,1,opt,name=SenderId,proto3" json:"SenderId,omitempty"`
 	Message              string               `protobuf:"bytes,2,opt,name=Message,proto3" json:"Message,omitempty"`
@@ -241,7 +393,7 @@ func (m *DirectMessage) Reset()         { *m = DirectMessage{} }
 func (m *DirectMessage) String() string { return proto.CompactTextString(m) }
 func (*DirectMessage) ProtoMessage()    {}
 func (*DirectMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8c585a45e2093e54, []int{5}
+	return fileDescriptor_8c585a45e2093e54, []int{8}
 }
 
 func (m *DirectMessage) XXX_Unmarshal(b []byte) error {
@@ -283,6 +435,181 @@ func (m *DirectMessage) GetTime() *timestamp.Timestamp {
 	return nil
 }
 
+// SearchRequest matches messages containing every word of Query. Peer is a
+// username, From and To bound the message time, all of them are optional.
+type SearchRequest struct {
+	Query                string               `protobuf:"bytes,1,opt,name=Query,proto3" json:"Query,omitempty"`
+	Peer                 string               `protobuf:"bytes,2,opt,name=Peer,proto3" json:"Peer,omitempty"`
+	From                 *timestamp.Timestamp `protobuf:"bytes,3,opt,name=From,proto3" json:"From,omitempty"`
+	To                   *timestamp.Timestamp `protobuf:"bytes,4,opt,name=To,proto3" json:"To,omitempty"`
+	Limit                int32                `protobuf:"varint,5,opt,name=Limit,proto3" json:"Limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *SearchRequest) Reset()         { *m = SearchRequest{} }
+func (m *SearchRequest) String() string { return proto.CompactTextString(m) }
+func (*SearchRequest) ProtoMessage()    {}
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8c585a45e2093e54, []int{9}
+}
+
+func (m *SearchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SearchRequest.Unmarshal(m, b)
+}
+func (m *SearchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SearchRequest.Marshal(b, m, deterministic)
+}
+func (m *SearchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SearchRequest.Merge(m, src)
+}
+func (m *SearchRequest) XXX_Size() int {
+	return xxx_messageInfo_SearchRequest.Size(m)
+}
+func (m *SearchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SearchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SearchRequest proto.InternalMessageInfo
+
+func (m *SearchRequest) GetQuery() string {
+	if m != nil {
+		return m.Query
+	}
+	return ""
+}
+
+func (m *SearchRequest) GetPeer() string {
+	if m != nil {
+		return m.Peer
+	}
+	return ""
+}
+
+func (m *SearchRequest) GetFrom() *timestamp.Timestamp {
+	if m != nil {
+		return m.From
+	}
+	return nil
+}
+
+func (m *SearchRequest) GetTo() *timestamp.Timestamp {
+	if m != nil {
+		return m.To
+	}
+	return nil
+}
+
+func (m *SearchRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type SearchResult struct {
+	Sender               string               `protobuf:"bytes,1,opt,name=Sender,proto3" json:"Sender,omitempty"`
+	Receiver             string               `protobuf:"bytes,2,opt,name=Receiver,proto3" json:"Receiver,omitempty"`
+	Message              string               `protobuf:"bytes,3,opt,name=Message,proto3" json:"Message,omitempty"`
+	Time                 *timestamp.Timestamp `protobuf:"bytes,4,opt,name=Time,proto3" json:"Time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *SearchResult) Reset()         { *m = SearchResult{} }
+func (m *SearchResult) String() string { return proto.CompactTextString(m) }
+func (*SearchResult) ProtoMessage()    {}
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8c585a45e2093e54, []int{10}
+}
+
+func (m *SearchResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SearchResult.Unmarshal(m, b)
+}
+func (m *SearchResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SearchResult.Marshal(b, m, deterministic)
+}
+func (m *SearchResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SearchResult.Merge(m, src)
+}
+func (m *SearchResult) XXX_Size() int {
+	return xxx_messageInfo_SearchResult.Size(m)
+}
+func (m *SearchResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_SearchResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SearchResult proto.InternalMessageInfo
+
+func (m *SearchResult) GetSender() string {
+	if m != nil {
+		return m.Sender
+	}
+	return ""
+}
+
+func (m *SearchResult) GetReceiver() string {
+	if m != nil {
+		return m.Receiver
+	}
+	return ""
+}
+
+func (m *SearchResult) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *SearchResult) GetTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.Time
+	}
+	return nil
+}
+
+type SearchResponse struct {
+	Results              []*SearchResult `protobuf:"bytes,1,rep,name=Results,proto3" json:"Results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *SearchResponse) Reset()         { *m = SearchResponse{} }
+func (m *SearchResponse) String() string { return proto.CompactTextString(m) }
+func (*SearchResponse) ProtoMessage()    {}
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8c585a45e2093e54, []int{11}
+}
+
+func (m *SearchResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SearchResponse.Unmarshal(m, b)
+}
+func (m *SearchResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SearchResponse.Marshal(b, m, deterministic)
+}
+func (m *SearchResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SearchResponse.Merge(m, src)
+}
+func (m *SearchResponse) XXX_Size() int {
+	return xxx_messageInfo_SearchResponse.Size(m)
+}
+func (m *SearchResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SearchResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SearchResponse proto.InternalMessageInfo
+
+func (m *SearchResponse) GetResults() []*SearchResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
 type SubscriptionRequest struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -293,7 +620,7 @@ func (m *SubscriptionRequest) Reset()         { *m = SubscriptionRequest{} }
 func (m *SubscriptionRequest) String() string { return proto.CompactTextString(m) }
 func (*SubscriptionRequest) ProtoMessage()    {}
 func (*SubscriptionRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8c585a45e2093e54, []int{6}
+	return fileDescriptor_8c585a45e2093e54, []int{12}
 }
 
 func (m *SubscriptionRequest) XXX_Unmarshal(b []byte) error {
@@ -326,7 +653,7 @@ func (m *UserStatusChange) Reset()         { *m = UserStatusChange{} }
 func (m *UserStatusChange) String() string { return proto.CompactTextString(m) }
 func (*UserStatusChange) ProtoMessage()    {}
 func (*UserStatusChange) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8c585a45e2093e54, []int{7}
+	return fileDescriptor_8c585a45e2093e54, []int{13}
 }
 
 func (m *UserStatusChange) XXX_Unmarshal(b []byte) error {
@@ -361,10 +688,106 @@ func (m *UserStatusChange) GetAdd() bool {
 	return false
 }
 
+type ProfileChanged struct {
+	Changed              *User    `protobuf:"bytes,1,opt,name=Changed,proto3" json:"Changed,omitempty"`
+	PreviousUsername     string   `protobuf:"bytes,2,opt,name=PreviousUsername,proto3" json:"PreviousUsername,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ProfileChanged) Reset()         { *m = ProfileChanged{} }
+func (m *ProfileChanged) String() string { return proto.CompactTextString(m) }
+func (*ProfileChanged) ProtoMessage()    {}
+func (*ProfileChanged) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8c585a45e2093e54, []int{14}
+}
+
+func (m *ProfileChanged) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ProfileChanged.Unmarshal(m, b)
+}
+func (m *ProfileChanged) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ProfileChanged.Marshal(b, m, deterministic)
+}
+func (m *ProfileChanged) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProfileChanged.Merge(m, src)
+}
+func (m *ProfileChanged) XXX_Size() int {
+	return xxx_messageInfo_ProfileChanged.Size(m)
+}
+func (m *ProfileChanged) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProfileChanged.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ProfileChanged proto.InternalMessageInfo
+
+func (m *ProfileChanged) GetChanged() *User {
+	if m != nil {
+		return m.Changed
+	}
+	return nil
+}
+
+func (m *ProfileChanged) GetPreviousUsername() string {
+	if m != nil {
+		return m.PreviousUsername
+	}
+	return ""
+}
+
+type Announcement struct {
+	Text                 string               `protobuf:"bytes,1,opt,name=Text,proto3" json:"Text,omitempty"`
+	Time                 *timestamp.Timestamp `protobuf:"bytes,2,opt,name=Time,proto3" json:"Time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Announcement) Reset()         { *m = Announcement{} }
+func (m *Announcement) String() string { return proto.CompactTextString(m) }
+func (*Announcement) ProtoMessage()    {}
+func (*Announcement) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8c585a45e2093e54, []int{15}
+}
+
+func (m *Announcement) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Announcement.Unmarshal(m, b)
+}
+func (m *Announcement) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Announcement.Marshal(b, m, deterministic)
+}
+func (m *Announcement) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Announcement.Merge(m, src)
+}
+func (m *Announcement) XXX_Size() int {
+	return xxx_messageInfo_Announcement.Size(m)
+}
+func (m *Announcement) XXX_DiscardUnknown() {
+	xxx_messageInfo_Announcement.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Announcement proto.InternalMessageInfo
+
+func (m *Announcement) GetText() string {
+	if m != nil {
+		return m.Text
+	}
+	return ""
+}
+
+func (m *Announcement) GetTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.Time
+	}
+	return nil
+}
+
 type ServerUpdate struct {
 	// Types that are valid to be assigned to Content:
 	//	*ServerUpdate_IncomingMessage
 	//	*ServerUpdate_UserOnlineStatus
+	//	*ServerUpdate_Announcement
+	//	*ServerUpdate_ProfileChanged
 	Content              isServerUpdate_Content `protobuf_oneof:"content"`
 	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
 	XXX_unrecognized     []byte                 `json:"-"`
@@ -375,7 +798,7 @@ func (m *ServerUpdate) Reset()         { *m = ServerUpdate{} }
 func (m *ServerUpdate) String() string { return proto.CompactTextString(m) }
 func (*ServerUpdate) ProtoMessage()    {}
 func (*ServerUpdate) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8c585a45e2093e54, []int{8}
+	return fileDescriptor_8c585a45e2093e54, []int{16}
 }
 
 func (m *ServerUpdate) XXX_Unmarshal(b []byte) error {
@@ -408,10 +831,22 @@ type ServerUpdate_UserOnlineStatus struct {
 	UserOnlineStatus *UserStatusChange `protobuf:"bytes,2,opt,name=user_online_status,json=userOnlineStatus,proto3,oneof"`
 }
 
+type ServerUpdate_Announcement struct {
+	Announcement *Announcement `protobuf:"bytes,3,opt,name=announcement,proto3,oneof"`
+}
+
+type ServerUpdate_ProfileChanged struct {
+	ProfileChanged *ProfileChanged `protobuf:"bytes,4,opt,name=profile_changed,json=profileChanged,proto3,oneof"`
+}
+
 func (*ServerUpdate_IncomingMessage) isServerUpdate_Content() {}
 
 func (*ServerUpdate_UserOnlineStatus) isServerUpdate_Content() {}
 
+func (*ServerUpdate_Announcement) isServerUpdate_Content() {}
+
+func (*ServerUpdate_ProfileChanged) isServerUpdate_Content() {}
+
 func (m *ServerUpdate) GetContent() isServerUpdate_Content {
 	if m != nil {
 		return m.Content
@@ -433,11 +868,27 @@ func (m *ServerUpdate) GetUserOnlineStatus() *UserStatusChange {
 	return nil
 }
 
+func (m *ServerUpdate) GetAnnouncement() *Announcement {
+	if x, ok := m.GetContent().(*ServerUpdate_Announcement); ok {
+		return x.Announcement
+	}
+	return nil
+}
+
+func (m *ServerUpdate) GetProfileChanged() *ProfileChanged {
+	if x, ok := m.GetContent().(*ServerUpdate_ProfileChanged); ok {
+		return x.ProfileChanged
+	}
+	return nil
+}
+
 // XXX_OneofWrappers is for the internal use of the proto package.
 func (*ServerUpdate) XXX_OneofWrappers() []interface{} {
 	return []interface{}{
 		(*ServerUpdate_IncomingMessage)(nil),
 		(*ServerUpdate_UserOnlineStatus)(nil),
+		(*ServerUpdate_Announcement)(nil),
+		(*ServerUpdate_ProfileChanged)(nil),
 	}
 }
 
@@ -446,10 +897,18 @@ func init() {
 	proto.RegisterType((*UserList)(nil), "UserList")
 	proto.RegisterType((*RegisterRequest)(nil), "RegisterRequest")
 	proto.RegisterType((*User)(nil), "User")
+	proto.RegisterType((*StatusRequest)(nil), "StatusRequest")
+	proto.RegisterType((*NicknameRequest)(nil), "NicknameRequest")
 	proto.RegisterType((*NewMessage)(nil), "NewMessage")
+	proto.RegisterType((*BlockRequest)(nil), "BlockRequest")
 	proto.RegisterType((*DirectMessage)(nil), "DirectMessage")
+	proto.RegisterType((*SearchRequest)(nil), "SearchRequest")
+	proto.RegisterType((*SearchResult)(nil), "SearchResult")
+	proto.RegisterType((*SearchResponse)(nil), "SearchResponse")
 	proto.RegisterType((*SubscriptionRequest)(nil), "SubscriptionRequest")
 	proto.RegisterType((*UserStatusChange)(nil), "UserStatusChange")
+	proto.RegisterType((*ProfileChanged)(nil), "ProfileChanged")
+	proto.RegisterType((*Announcement)(nil), "Announcement")
 	proto.RegisterType((*ServerUpdate)(nil), "ServerUpdate")
 }
 
@@ -458,37 +917,60 @@ func init() {
 }
 
 var fileDescriptor_8c585a45e2093e54 = []byte{
-	// 473 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x52, 0x5b, 0x6f, 0xd3, 0x3e,
-	0x14, 0x5f, 0x7a, 0x59, 0xd3, 0xd3, 0x5d, 0x32, 0xff, 0xff, 0x48, 0x25, 0x20, 0x56, 0x85, 0x07,
-	0xfa, 0x82, 0x37, 0x65, 0x8f, 0x3c, 0x6d, 0x6c, 0xb0, 0x49, 0x5c, 0x24, 0x77, 0x7b, 0xe1, 0xa5,
-	0x4a, 0x93, 0x43, 0x66, 0x69, 0x71, 0x82, 0xed, 0x0c, 0xf1, 0x51, 0xf8, 0x5c, 0x7c, 0x21, 0x14,
-	0x3b, 0x0e, 0x6d, 0x05, 0x4f, 0xf1, 0xb1, 0xcf, 0x39, 0xf9, 0xdd, 0x00, 0xd2, 0xfb, 0x44, 0xd3,
-	0x4a, 0x96, 0xba, 0x0c, 0x8f, 0xf3, 0xb2, 0xcc, 0x1f, 0xf0, 0xc4, 0x54, 0xab, 0xfa, 0xeb, 0x89,
-	0xe6, 0x05, 0x2a, 0x9d, 0x14, 0x95, 0x6d, 0x88, 0x46, 0x30, 0xbc, 0x2a, 0x2a, 0xfd, 0x23, 0x7a,
-	0x05, 0xfe, 0x9d, 0x42, 0xf9, 0x81, 0x2b, 0x4d, 0x9e, 0xc1, 0xb0, 0x56, 0x28, 0xd5, 0xd4, 0x9b,
-	0xf5, 0xe7, 0x93, 0x78, 0x48, 0x9b, 0x17, 0x66, 0xef, 0xa2, 0xd7, 0x70, 0xc8, 0x30, 0xe7, 0x4a,
-	0xa3, 0x64, 0xf8, 0xad, 0x46, 0xa5, 0x49, 0x68, 0x67, 0x45, 0x52, 0xe0, 0xd4, 0x9b, 0x79, 0xf3,
-	0x31, 0xeb, 0xea, 0x28, 0x86, 0x41, 0x73, 0x26, 0x07, 0xd0, 0xbb, 0xc9, 0xda, 0xd7, 0xde, 0x4d,
-	0xb6, 0x31, 0xd3, 0xdb, 0x9a, 0x79, 0x07, 0xf0, 0x09, 0xbf, 0x7f, 0x44, 0xa5, 0x92, 0x1c, 0xc9,
-	0x0b, 0x00, 0x86, 0x29, 0xf2, 0x47, 0x94, 0xdd, 0x86, 0xb5, 0x1b, 0x32, 0x85, 0x51, 0xdb, 0xda,
-	0x2e, 0x72, 0x65, 0x54, 0xc3, 0xfe, 0x25, 0x97, 0x98, 0x6a, 0xb7, 0x2a, 0x04, 0x7f, 0x81, 0x22,
-	0x5b, 0x5b, 0xd4, 0xd5, 0xff, 0x5e, 0x43, 0x28, 0x0c, 0x6e, 0x79, 0x81, 0xd3, 0xfe, 0xcc, 0x9b,
-	0x4f, 0xe2, 0x90, 0x5a, 0x4d, 0xa9, 0xd3, 0x94, 0xde, 0x3a, 0x4d, 0x99, 0xe9, 0x8b, 0x9e, 0xc0,
-	0x7f, 0x8b, 0x7a, 0xa5, 0x52, 0xc9, 0x2b, 0xcd, 0x4b, 0xd1, 0xaa, 0x14, 0x5d, 0x41, 0xd0, 0x30,
-	0x5c, 0xe8, 0x44, 0xd7, 0xea, 0xed, 0x7d, 0x22, 0x72, 0x24, 0xc7, 0x30, 0xb2, 0x27, 0x8b, 0xa7,
-	0xd3, 0xda, 0xdd, 0x92, 0x00, 0xfa, 0xe7, 0x59, 0x66, 0x10, 0xf9, 0xac, 0x39, 0x46, 0x3f, 0x3d,
-	0xd8, 0x5b, 0xa0, 0x7c, 0x44, 0x79, 0x57, 0x65, 0x89, 0x46, 0xf2, 0x06, 0x02, 0x2e, 0xd2, 0xb2,
-	0xe0, 0x22, 0x5f, 0x16, 0x2d, 0x03, 0xbb, 0xec, 0x80, 0x6e, 0xd0, 0xbf, 0xde, 0x61, 0x87, 0xae,
-	0xd3, 0x71, 0x3b, 0x07, 0xd2, 0xd8, 0xba, 0x2c, 0xc5, 0x03, 0x17, 0xb8, 0x54, 0x06, 0x9c, 0xf9,
-	0xdd, 0x24, 0x3e, 0xa2, 0xdb, 0x78, 0xaf, 0x77, 0x58, 0xd0, 0xb4, 0x7f, 0x36, 0xdd, 0xf6, 0xe5,
-	0x62, 0x0c, 0xa3, 0xb4, 0x14, 0x1a, 0x85, 0x8e, 0x7f, 0x79, 0xb0, 0xe7, 0xc2, 0x61, 0x5c, 0x7f,
-	0x09, 0xbe, 0xab, 0x49, 0x40, 0xb7, 0x72, 0x13, 0x5a, 0xb2, 0xe4, 0x29, 0x0c, 0x4c, 0xec, 0x76,
-	0xa9, 0x89, 0x62, 0x38, 0xa6, 0x5d, 0x12, 0x4f, 0xe1, 0xa8, 0x31, 0x68, 0xd3, 0xc5, 0x09, 0xfd,
-	0x93, 0x8e, 0x70, 0x8b, 0x23, 0x39, 0x03, 0x78, 0x8f, 0xda, 0x4a, 0xa3, 0xc8, 0xff, 0xf4, 0x2f,
-	0x4e, 0x84, 0xfb, 0x74, 0x5d, 0xc0, 0x53, 0x8f, 0x3c, 0x07, 0xb8, 0x44, 0xe9, 0x80, 0x3a, 0x1c,
-	0xed, 0xf7, 0xc2, 0xff, 0xb2, 0x6b, 0xbc, 0x56, 0x2b, 0xfb, 0x3d, 0xfb, 0x1d, 0x00, 0x00, 0xff,
-	0xff, 0xee, 0x04, 0xfe, 0x41, 0x63, 0x03, 0x00, 0x00,
+	// 841 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x55, 0x5f, 0x6f, 0xdb, 0x36,
+	0x10, 0x8f, 0x65, 0x3b, 0xb6, 0xcf, 0x7f, 0xcb, 0x75, 0x83, 0xa7, 0x0d, 0xab, 0xa1, 0x0d, 0x4b,
+	0xd6, 0x07, 0xb6, 0x4b, 0x9e, 0xb6, 0x3d, 0x25, 0x4b, 0xbb, 0x04, 0xe8, 0xd2, 0x4c, 0x76, 0x5e,
+	0x06, 0x0c, 0x81, 0x22, 0x5f, 0x1d, 0xa2, 0x96, 0xa8, 0x91, 0x54, 0xba, 0x7c, 0x85, 0x61, 0xdf,
+	0x64, 0x9f, 0x62, 0xdf, 0x6c, 0x20, 0x29, 0xaa, 0x92, 0x9b, 0x22, 0x7e, 0x12, 0x8f, 0xf7, 0xd3,
+	0xdd, 0xf1, 0x77, 0x3f, 0x1e, 0x01, 0xe2, 0x9b, 0x48, 0xd1, 0x4c, 0x70, 0xc5, 0xfd, 0x27, 0x2b,
+	0xce, 0x57, 0x6b, 0x7c, 0x66, 0xac, 0xeb, 0xfc, 0xcd, 0x33, 0xc5, 0x12, 0x94, 0x2a, 0x4a, 0x32,
+	0x0b, 0x08, 0x3a, 0xd0, 0x7e, 0x91, 0x64, 0xea, 0x2e, 0xd8, 0x83, 0xee, 0xa5, 0x44, 0xf1, 0x8a,
+	0x49, 0x45, 0xbe, 0x80, 0x76, 0x2e, 0x51, 0xc8, 0x69, 0x63, 0xd6, 0xdc, 0xef, 0x1f, 0xb4, 0xa9,
+	0xf6, 0x84, 0x76, 0x2f, 0x38, 0x83, 0x71, 0x88, 0x2b, 0x26, 0x15, 0x8a, 0x10, 0xff, 0xcc, 0x51,
+	0x2a, 0xe2, 0xdb, 0x7f, 0xd3, 0x28, 0xc1, 0x69, 0x63, 0xd6, 0xd8, 0xef, 0x85, 0xa5, 0xad, 0x7d,
+	0x17, 0x91, 0x94, 0xef, 0xb8, 0x58, 0x4e, 0x3d, 0xeb, 0x73, 0x76, 0xb0, 0x86, 0x96, 0xc6, 0x91,
+	0x11, 0x78, 0x67, 0xcb, 0xe2, 0x4f, 0xef, 0x6c, 0x59, 0x8b, 0xe7, 0x6d, 0xc4, 0xfb, 0x0c, 0x76,
+	0xe7, 0x2a, 0x52, 0xb9, 0x9c, 0x36, 0x8d, 0xa7, 0xb0, 0xc8, 0x0c, 0xfa, 0x27, 0x4c, 0x66, 0xeb,
+	0xe8, 0xee, 0x5c, 0xff, 0xd6, 0x32, 0xce, 0xea, 0x56, 0xb0, 0x07, 0x43, 0x8b, 0x75, 0x65, 0xbf,
+	0x0f, 0xd5, 0xa8, 0x86, 0x0a, 0x5e, 0xc3, 0xf8, 0x9c, 0xc5, 0x6f, 0x75, 0xba, 0x6d, 0x4e, 0xb8,
+	0x91, 0xd9, 0xfb, 0x30, 0xf3, 0x4b, 0x80, 0x73, 0x7c, 0xf7, 0x2b, 0x4a, 0x19, 0xad, 0x90, 0x7c,
+	0x05, 0x10, 0x62, 0x8c, 0xec, 0x16, 0x45, 0x79, 0xea, 0xca, 0x0e, 0x99, 0x42, 0xa7, 0x80, 0x16,
+	0xb1, 0x9c, 0x19, 0x7c, 0x0b, 0x83, 0xe3, 0x35, 0x8f, 0xdf, 0x56, 0x0e, 0x70, 0x29, 0x2b, 0x51,
+	0x0a, 0x2b, 0xc8, 0x61, 0x78, 0xc2, 0x04, 0xc6, 0xca, 0xa5, 0xf4, 0xa1, 0x3b, 0xc7, 0x74, 0x59,
+	0x81, 0x96, 0xf6, 0xc7, 0xd3, 0x11, 0x0a, 0xad, 0x05, 0x4b, 0xd0, 0x10, 0xdd, 0x3f, 0xf0, 0xa9,
+	0xd5, 0x12, 0x75, 0x5a, 0xa2, 0x0b, 0xa7, 0xa5, 0xd0, 0xe0, 0x82, 0x7f, 0x1b, 0x30, 0x9c, 0x63,
+	0x24, 0xe2, 0x1b, 0x57, 0xe0, 0x63, 0x68, 0xff, 0x96, 0xa3, 0xb8, 0x2b, 0x92, 0x5a, 0x83, 0x10,
+	0x68, 0x5d, 0x20, 0x8a, 0x22, 0x9d, 0x59, 0xeb, 0x5c, 0x2f, 0x05, 0x4f, 0xb6, 0xc9, 0xa5, 0x71,
+	0xe4, 0x29, 0x78, 0x0b, 0x3e, 0x6d, 0x3d, 0x88, 0xf6, 0x16, 0x5c, 0x57, 0xf1, 0x8a, 0x25, 0x4c,
+	0x4d, 0xdb, 0xb3, 0xc6, 0x7e, 0x3b, 0xb4, 0x46, 0xf0, 0x4f, 0x03, 0x06, 0xae, 0x5a, 0x99, 0xaf,
+	0xad, 0x1c, 0x0c, 0x29, 0xa5, 0x1c, 0x8c, 0xa5, 0xc9, 0x73, 0xdd, 0x71, 0x6a, 0x74, 0x76, 0x95,
+	0xbc, 0xe6, 0xfd, 0xe4, 0xb5, 0xb6, 0x24, 0xef, 0x07, 0x18, 0x95, 0xd5, 0x64, 0x3c, 0x95, 0x48,
+	0xf6, 0xa0, 0x63, 0x2b, 0x73, 0xf7, 0x70, 0x48, 0xab, 0xf5, 0x86, 0xce, 0x1b, 0x7c, 0x0a, 0x9f,
+	0xcc, 0xf3, 0x6b, 0x19, 0x0b, 0x96, 0x29, 0xc6, 0xd3, 0x82, 0xfc, 0xe0, 0x05, 0x4c, 0xb4, 0x1e,
+	0xac, 0xa8, 0x7f, 0xbe, 0x89, 0xd2, 0x15, 0x92, 0x27, 0xd0, 0xb1, 0x2b, 0xab, 0x83, 0xf2, 0x6e,
+	0xbb, 0x5d, 0x32, 0x81, 0xe6, 0xd1, 0xd2, 0xde, 0xd4, 0x6e, 0xa8, 0x97, 0xc1, 0x1f, 0x30, 0xba,
+	0x10, 0xfc, 0x0d, 0x5b, 0xa3, 0xc3, 0x3c, 0x18, 0xe4, 0x29, 0x4c, 0x2e, 0x04, 0xde, 0x32, 0x9e,
+	0xcb, 0x8d, 0x7b, 0xfc, 0xc1, 0x7e, 0x10, 0xc2, 0xe0, 0x28, 0x4d, 0x79, 0x9e, 0xc6, 0x98, 0x60,
+	0xaa, 0xb4, 0x38, 0x16, 0xf8, 0x97, 0x2a, 0x7a, 0x60, 0xd6, 0x25, 0x97, 0xde, 0x96, 0x5c, 0xfe,
+	0xed, 0xe9, 0xd6, 0x8a, 0x5b, 0x14, 0x97, 0xd9, 0x32, 0x52, 0x48, 0x7e, 0x82, 0x09, 0x4b, 0x63,
+	0x9e, 0xb0, 0x74, 0x75, 0x95, 0x14, 0xfd, 0xb2, 0xa5, 0x8f, 0x68, 0xed, 0xa6, 0x9c, 0xee, 0x84,
+	0x63, 0x87, 0x74, 0x9d, 0x3c, 0x02, 0xa2, 0x27, 0xdf, 0x15, 0x4f, 0xd7, 0x2c, 0xc5, 0x2b, 0x69,
+	0x47, 0x86, 0xad, 0xe5, 0x11, 0xdd, 0xa4, 0xf8, 0x74, 0x27, 0x9c, 0x68, 0xf8, 0x6b, 0x83, 0xb6,
+	0x1e, 0x72, 0x08, 0x83, 0xa8, 0x72, 0xc8, 0x42, 0xe5, 0x43, 0x5a, 0x3d, 0xf9, 0xe9, 0x4e, 0x58,
+	0x03, 0x91, 0x1f, 0x61, 0x9c, 0x59, 0xe2, 0xaf, 0xe2, 0x82, 0x6e, 0x2b, 0xa6, 0x31, 0xad, 0x37,
+	0xe4, 0x74, 0x27, 0x1c, 0x65, 0xb5, 0x9d, 0xe3, 0x1e, 0x74, 0x62, 0x9e, 0x2a, 0x4c, 0xd5, 0xc1,
+	0x7f, 0x4d, 0x18, 0xb8, 0x81, 0x6d, 0xa6, 0xed, 0xd7, 0xd0, 0x75, 0x36, 0x99, 0xd0, 0x8d, 0x59,
+	0xee, 0xdb, 0x5e, 0x92, 0xcf, 0xa1, 0x65, 0x9e, 0x82, 0x5d, 0x6a, 0x9e, 0x07, 0xbf, 0x47, 0xcb,
+	0xd7, 0xe1, 0x39, 0x3c, 0xd2, 0x37, 0xa3, 0x3e, 0x61, 0xfa, 0xf4, 0xfd, 0x84, 0xf3, 0x37, 0x48,
+	0x25, 0x87, 0x00, 0xbf, 0xa0, 0xb2, 0xbd, 0x90, 0xe4, 0x31, 0xbd, 0x47, 0xad, 0xfe, 0x90, 0x56,
+	0x3b, 0xf6, 0xbc, 0x41, 0xbe, 0x04, 0x38, 0x41, 0xe1, 0x0a, 0x75, 0x75, 0x14, 0x5f, 0x12, 0x40,
+	0xcf, 0x8c, 0x42, 0x53, 0xec, 0x90, 0x56, 0xc7, 0x62, 0x89, 0xf9, 0x06, 0xfa, 0x97, 0xe9, 0xf5,
+	0x43, 0xa8, 0x19, 0xf4, 0xf5, 0xb1, 0x8c, 0x0f, 0x97, 0xf7, 0x1d, 0x38, 0x80, 0xde, 0x1c, 0x55,
+	0xd1, 0xca, 0x11, 0xad, 0x3d, 0x22, 0x8e, 0xaf, 0xef, 0x60, 0x64, 0xb9, 0x77, 0x2f, 0x07, 0x99,
+	0xd0, 0x8d, 0x47, 0xc4, 0x41, 0xbf, 0x77, 0x37, 0xbd, 0xa0, 0xc7, 0xc4, 0xac, 0x8e, 0x4d, 0x7f,
+	0x4c, 0xeb, 0xa3, 0xe0, 0xb8, 0xfb, 0xfb, 0xae, 0x11, 0xbb, 0xbc, 0xb6, 0xdf, 0xc3, 0xff, 0x07,
+	0x00, 0xdd, 0x42, 0x9c, 0xcf, 0xe5, 0x07, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -508,6 +990,17 @@ type RegisterUserClient interface {
 	SendDirectMessage(ctx context.Context, in *NewMessage, opts ...grpc.CallOption) (*DirectMessage, error)
 	GetUpdates(ctx context.Context, in *SubscriptionRequest, opts ...grpc.CallOption) (RegisterUser_GetUpdatesClient, error)
 	Deregister(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	// blocked users can't message the caller and are hidden from its list
+	BlockUser(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*Empty, error)
+	UnblockUser(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*Empty, error)
+	// blocks of accounts are kept across sessions, those of guests end with
+	// the session
+	ListBlocked(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*UserList, error)
+	SetStatus(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*User, error)
+	// renames the caller, the id stays and the username must be free
+	ChangeNickname(ctx context.Context, in *NicknameRequest, opts ...grpc.CallOption) (*User, error)
+	// full-text search over the stored conversations of the caller
+	SearchMessages(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
 }
 
 type registerUserClient struct {
@@ -586,6 +1079,60 @@ func (c *registerUserClient) Deregister(ctx context.Context, in *Empty, opts ...
 	return out, nil
 }
 
+func (c *registerUserClient) BlockUser(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/RegisterUser/BlockUser", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *registerUserClient) UnblockUser(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/RegisterUser/UnblockUser", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *registerUserClient) ListBlocked(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*UserList, error) {
+	out := new(UserList)
+	err := c.cc.Invoke(ctx, "/RegisterUser/ListBlocked", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *registerUserClient) SetStatus(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*User, error) {
+	out := new(User)
+	err := c.cc.Invoke(ctx, "/RegisterUser/SetStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *registerUserClient) ChangeNickname(ctx context.Context, in *NicknameRequest, opts ...grpc.CallOption) (*User, error) {
+	out := new(User)
+	err := c.cc.Invoke(ctx, "/RegisterUser/ChangeNickname", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *registerUserClient) SearchMessages(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, "/RegisterUser/SearchMessages", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RegisterUserServer is the server API for RegisterUser service.
 type RegisterUserServer interface {
 	Register(context.Context, *RegisterRequest) (*User, error)
@@ -593,6 +1140,17 @@ type RegisterUserServer interface {
 	SendDirectMessage(context.Context, *NewMessage) (*DirectMessage, error)
 	GetUpdates(*SubscriptionRequest, RegisterUser_GetUpdatesServer) error
 	Deregister(context.Context, *Empty) (*Empty, error)
+	// blocked users can't message the caller and are hidden from its list
+	BlockUser(context.Context, *BlockRequest) (*Empty, error)
+	UnblockUser(context.Context, *BlockRequest) (*Empty, error)
+	// blocks of accounts are kept across sessions, those of guests end with
+	// the session
+	ListBlocked(context.Context, *Empty) (*UserList, error)
+	SetStatus(context.Context, *StatusRequest) (*User, error)
+	// renames the caller, the id stays and the username must be free
+	ChangeNickname(context.Context, *NicknameRequest) (*User, error)
+	// full-text search over the stored conversations of the caller
+	SearchMessages(context.Context, *SearchRequest) (*SearchResponse, error)
 }
 
 // UnimplementedRegisterUserServer can be embedded to have forward compatible implementations.
@@ -614,6 +1172,24 @@ func (*UnimplementedRegisterUserServer) GetUpdates(req *SubscriptionRequest, srv
 func (*UnimplementedRegisterUserServer) Deregister(ctx context.Context, req *Empty) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Deregister not implemented")
 }
+func (*UnimplementedRegisterUserServer) BlockUser(ctx context.Context, req *BlockRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BlockUser not implemented")
+}
+func (*UnimplementedRegisterUserServer) UnblockUser(ctx context.Context, req *BlockRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnblockUser not implemented")
+}
+func (*UnimplementedRegisterUserServer) ListBlocked(ctx context.Context, req *Empty) (*UserList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBlocked not implemented")
+}
+func (*UnimplementedRegisterUserServer) SetStatus(ctx context.Context, req *StatusRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetStatus not implemented")
+}
+func (*UnimplementedRegisterUserServer) ChangeNickname(ctx context.Context, req *NicknameRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangeNickname not implemented")
+}
+func (*UnimplementedRegisterUserServer) SearchMessages(ctx context.Context, req *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchMessages not implemented")
+}
 
 func RegisterRegisterUserServer(s *grpc.Server, srv RegisterUserServer) {
 	s.RegisterService(&_RegisterUser_serviceDesc, srv)
@@ -712,6 +1288,114 @@ func _RegisterUser_Deregister_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RegisterUser_BlockUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegisterUserServer).BlockUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/RegisterUser/BlockUser",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegisterUserServer).BlockUser(ctx, req.(*BlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RegisterUser_UnblockUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegisterUserServer).UnblockUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/RegisterUser/UnblockUser",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegisterUserServer).UnblockUser(ctx, req.(*BlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RegisterUser_ListBlocked_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegisterUserServer).ListBlocked(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/RegisterUser/ListBlocked",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegisterUserServer).ListBlocked(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RegisterUser_SetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegisterUserServer).SetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/RegisterUser/SetStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegisterUserServer).SetStatus(ctx, req.(*StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RegisterUser_ChangeNickname_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NicknameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegisterUserServer).ChangeNickname(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/RegisterUser/ChangeNickname",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegisterUserServer).ChangeNickname(ctx, req.(*NicknameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RegisterUser_SearchMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegisterUserServer).SearchMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/RegisterUser/SearchMessages",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegisterUserServer).SearchMessages(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _RegisterUser_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "RegisterUser",
 	HandlerType: (*RegisterUserServer)(nil),
@@ -732,6 +1416,30 @@ var _RegisterUser_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Deregister",
 			Handler:    _RegisterUser_Deregister_Handler,
 		},
+		{
+			MethodName: "BlockUser",
+			Handler:    _RegisterUser_BlockUser_Handler,
+		},
+		{
+			MethodName: "UnblockUser",
+			Handler:    _RegisterUser_UnblockUser_Handler,
+		},
+		{
+			MethodName: "ListBlocked",
+			Handler:    _RegisterUser_ListBlocked_Handler,
+		},
+		{
+			MethodName: "SetStatus",
+			Handler:    _RegisterUser_SetStatus_Handler,
+		},
+		{
+			MethodName: "ChangeNickname",
+			Handler:    _RegisterUser_ChangeNickname_Handler,
+		},
+		{
+			MethodName: "SearchMessages",
+			Handler:    _RegisterUser_SearchMessages_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
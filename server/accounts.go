@@ -0,0 +1,43 @@
+package server
+
+import (
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// passwordCost is the bcrypt cost of new server.accounts entries, entries
+// keep the cost they were hashed with.
+var passwordCost = 12
+
+// HashPassword returns the server.accounts entry of a password, a bcrypt hash.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), passwordCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// parseAccount checks that an accounts entry is a bcrypt hash.
+func parseAccount(entry string) error {
+	if _, err := bcrypt.Cost([]byte(entry)); err != nil {
+		return fmt.Errorf("expected a bcrypt hash: %w", err)
+	}
+	return nil
+}
+
+// validateAccounts rejects malformed entries at start instead of at login.
+func validateAccounts(accounts map[string]string) error {
+	for username, entry := range accounts {
+		if err := parseAccount(entry); err != nil {
+			return fmt.Errorf("server.accounts.%s: %w", username, err)
+		}
+	}
+	return nil
+}
+
+// checkPassword reports whether the password matches an accounts entry. It is
+// slow on purpose, callers must not hold s.mu.
+func checkPassword(entry, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(entry), []byte(password)) == nil
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"golang.org/x/crypto/bcrypt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// the default cost takes seconds per hash under the race detector
+	passwordCost = bcrypt.MinCost
+	os.Exit(m.Run())
+}
+
+func TestPasswordEntries(t *testing.T) {
+	entry, err := HashPassword("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateAccounts(map[string]string{"alice": entry}); err != nil {
+		t.Fatalf("generated entry rejected: %v", err)
+	}
+	if !checkPassword(entry, "secret") {
+		t.Error("password doesn't match its entry")
+	}
+	if checkPassword(entry, "Secret") {
+		t.Error("wrong password matches")
+	}
+	if other, _ := HashPassword("secret"); other == entry {
+		t.Error("entries are not salted")
+	}
+	if _, err := HashPassword(strings.Repeat("x", 73)); err == nil {
+		t.Error("password longer than bcrypt takes was hashed")
+	}
+}
+
+func TestValidateAccountsRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry string
+	}{
+		{"empty", ""},
+		{"plain text", "secret"},
+		{"truncated bcrypt", "$2a$12$abc"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := validateAccounts(map[string]string{"alice": test.entry}); err == nil {
+				t.Error("entry accepted")
+			}
+			if checkPassword(test.entry, "secret") {
+				t.Error("password matches a malformed entry")
+			}
+		})
+	}
+}
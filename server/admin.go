@@ -0,0 +1,161 @@
+package server
+
+import (
+	"chat/logging"
+	"chat/protos"
+	"context"
+	"crypto/subtle"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"strings"
+	"time"
+)
+
+const adminMethodPrefix = "/Admin/"
+
+// AdminBackend implements the operator service on top of the chat backend.
+type AdminBackend struct {
+	chat *GrpcBackend
+}
+
+func NewAdminImplementation(chat *GrpcBackend) *AdminBackend {
+	return &AdminBackend{chat: chat}
+}
+
+// AdminEnabled reports whether an admin token was configured.
+func (s *GrpcBackend) AdminEnabled() bool {
+	return s.adminToken != ""
+}
+
+func adminMethod(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, adminMethodPrefix)
+}
+
+func (s *GrpcBackend) validateAdminToken(ctx context.Context) error {
+	if !s.AdminEnabled() {
+		return status.Error(codes.PermissionDenied, "admin service is disabled")
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	tokens := md.Get("admin-token")
+	if len(tokens) == 0 || subtle.ConstantTimeCompare([]byte(tokens[0]), []byte(s.adminToken)) != 1 {
+		return status.Error(codes.Unauthenticated, "invalid admin token")
+	}
+	return nil
+}
+
+func (a *AdminBackend) ListSessions(context.Context, *protos.Empty) (*protos.SessionList, error) {
+	a.chat.mu.RLock()
+	defer a.chat.mu.RUnlock()
+
+	now := time.Now()
+	sessions := make([]*protos.Session, 0, len(a.chat.onlineUsers))
+	for _, user := range a.chat.onlineUsers {
+		session := &protos.Session{
+			User:          user.proto,
+			Registered:    timestamppb.New(user.registered),
+			QueueDepth:    int32(len(user.updates)),
+			QueueCapacity: int32(cap(user.updates)),
+		}
+		if !user.streamStarted.IsZero() {
+			session.StreamStarted = timestamppb.New(user.streamStarted)
+			session.StreamAgeSeconds = int64(now.Sub(user.streamStarted).Seconds())
+		}
+		sessions = append(sessions, session)
+	}
+	return &protos.SessionList{Sessions: sessions}, nil
+}
+
+// kick sends a last notice to the user and takes it offline.
+func (a *AdminBackend) kick(clientId string, user *User, reason string) {
+	user.enqueue(newAnnouncement("disconnected by an operator: " + reason))
+	a.chat.removeUser(clientId)
+}
+
+func (a *AdminBackend) KickUser(ctx context.Context, request *protos.KickRequest) (*protos.Empty, error) {
+	a.chat.mu.RLock()
+	user, online := a.chat.onlineUsers[request.UserId]
+	var username string
+	if online {
+		// ChangeNickname and SetStatus replace proto under the lock
+		username = user.proto.Username
+	}
+	a.chat.mu.RUnlock()
+	if !online {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	a.kick(request.UserId, user, request.Reason)
+	logging.FromContext(ctx).Info("user kicked", "username", username, "reason", request.Reason)
+	return &protos.Empty{}, nil
+}
+
+func (a *AdminBackend) BanUsername(ctx context.Context, request *protos.BanRequest) (*protos.Empty, error) {
+	if request.Username == "" {
+		return nil, status.Error(codes.InvalidArgument, "username is required")
+	}
+
+	a.chat.mu.Lock()
+	a.chat.banned[request.Username] = request.Reason
+	var online *User
+	var onlineId string
+	for id, user := range a.chat.onlineUsers {
+		if user.proto.Username == request.Username {
+			online, onlineId = user, id
+		}
+	}
+	a.chat.mu.Unlock()
+
+	if online != nil {
+		a.kick(onlineId, online, "banned: "+request.Reason)
+	}
+	logging.FromContext(ctx).Info("username banned", "username", request.Username, "reason", request.Reason, "was_online", online != nil)
+	return &protos.Empty{}, nil
+}
+
+func (a *AdminBackend) BroadcastAnnouncement(ctx context.Context, request *protos.AnnouncementRequest) (*protos.Empty, error) {
+	if request.Text == "" {
+		return nil, status.Error(codes.InvalidArgument, "announcement text is required")
+	}
+
+	a.chat.mu.RLock()
+	a.chat.broadcastLocked(newAnnouncement(request.Text), nil)
+	a.chat.mu.RUnlock()
+
+	logging.FromContext(ctx).Info("announcement broadcast", "text", request.Text)
+	return &protos.Empty{}, nil
+}
+
+func (a *AdminBackend) GetServerStats(context.Context, *protos.Empty) (*protos.ServerStats, error) {
+	a.chat.mu.RLock()
+	defer a.chat.mu.RUnlock()
+
+	openStreams := 0
+	for _, user := range a.chat.onlineUsers {
+		if !user.streamStarted.IsZero() {
+			openStreams++
+		}
+	}
+
+	stats := &a.chat.stats
+	return &protos.ServerStats{
+		Started:           timestamppb.New(stats.started),
+		UptimeSeconds:     int64(time.Since(stats.started).Seconds()),
+		OnlineUsers:       int32(len(a.chat.onlineUsers)),
+		OpenStreams:       int32(openStreams),
+		Registrations:     stats.registrations.Load(),
+		MessagesForwarded: stats.messagesForwarded.Load(),
+		MessagesDropped:   stats.messagesDropped.Load(),
+		BannedUsernames:   int32(len(a.chat.banned)),
+	}, nil
+}
+
+func newAnnouncement(text string) *protos.ServerUpdate {
+	return &protos.ServerUpdate{Content: &protos.ServerUpdate_Announcement{
+		Announcement: &protos.Announcement{
+			Text: text,
+			Time: timestamppb.Now(),
+		},
+	}}
+}
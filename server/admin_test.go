@@ -0,0 +1,37 @@
+package server
+
+import (
+	"chat/protos"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestKickDuringRename(t *testing.T) {
+	backend := newTestBackend(t)
+	admin := NewAdminImplementation(backend)
+	// the renames must run in parallel with the kicks to race
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(max(4, runtime.GOMAXPROCS(0))))
+
+	for n := 0; n < 2000; n++ {
+		mallory := register(t, backend, "mallory")
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			backend.ChangeNickname(clientContext(mallory), &protos.NicknameRequest{Username: "not-mallory"})
+		}()
+		if _, err := admin.KickUser(clientContext(""), &protos.KickRequest{UserId: mallory, Reason: "spam"}); err != nil {
+			t.Fatalf("kick: %v", err)
+		}
+		wg.Wait()
+	}
+
+	list, err := backend.List(clientContext(""), &protos.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Users) != 0 {
+		t.Errorf("kicked users are listed: %v", list.Users)
+	}
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"chat/logging"
+	"chat/protos"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrBlocked is returned to senders the receiver has blocked.
+var ErrBlocked = status.Error(codes.PermissionDenied, "you are blocked by this user")
+
+// blockedUser is an entry of a block list, the id and username are those last
+// seen and let the blocker unblock accounts that are offline.
+type blockedUser struct {
+	id       string
+	username string
+}
+
+// blockedLocked reports whether the recipient blocked the other user. The
+// caller holds s.mu.
+func (s *GrpcBackend) blockedLocked(recipient, other *User) bool {
+	_, blocked := s.blocks[recipient.identity()][other.identity()]
+	return blocked
+}
+
+func (s *GrpcBackend) BlockUser(ctx context.Context, request *protos.BlockRequest) (*protos.Empty, error) {
+	clientId, _ := getClientIdFromContext(ctx)
+	if request.UserId == clientId {
+		return nil, status.Error(codes.InvalidArgument, "can't block yourself")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	blocker := s.onlineUsers[clientId]
+	blocked, online := s.onlineUsers[request.UserId]
+	if blocker == nil || !online {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	if blocker.identity() == blocked.identity() {
+		return nil, status.Error(codes.InvalidArgument, "can't block yourself")
+	}
+	list := s.blocks[blocker.identity()]
+	if list == nil {
+		list = make(map[string]blockedUser)
+		s.blocks[blocker.identity()] = list
+	}
+	list[blocked.identity()] = blockedUser{id: blocked.proto.Id, username: blocked.proto.Username}
+
+	// the blocked user leaves the blocker's list
+	blocker.enqueue(&protos.ServerUpdate{Content: &protos.ServerUpdate_UserOnlineStatus{
+		UserOnlineStatus: &protos.UserStatusChange{Changed: blocked.proto, Add: false},
+	}})
+
+	logging.FromContext(ctx).Info("user blocked", "blocked_id", blocked.proto.Id, "blocked_identity", blocked.identity())
+	return &protos.Empty{}, nil
+}
+
+// UnblockUser takes the id of an online user or the id a blocked account had
+// when it was last seen.
+func (s *GrpcBackend) UnblockUser(ctx context.Context, request *protos.BlockRequest) (*protos.Empty, error) {
+	clientId, _ := getClientIdFromContext(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	blocker := s.onlineUsers[clientId]
+	if blocker == nil {
+		return nil, status.Error(codes.NotFound, "user is not blocked")
+	}
+	list := s.blocks[blocker.identity()]
+	identity := ""
+	if unblocked, online := s.onlineUsers[request.UserId]; online {
+		if _, blocked := list[unblocked.identity()]; blocked {
+			identity = unblocked.identity()
+		}
+	}
+	for blockedIdentity, entry := range list {
+		if identity == "" && entry.id == request.UserId {
+			identity = blockedIdentity
+		}
+	}
+	if identity == "" {
+		return nil, status.Error(codes.NotFound, "user is not blocked")
+	}
+	delete(list, identity)
+	if len(list) == 0 {
+		delete(s.blocks, blocker.identity())
+	}
+
+	// show the user again if it is online
+	for _, user := range s.onlineUsers {
+		if user.identity() == identity {
+			blocker.enqueue(&protos.ServerUpdate{Content: &protos.ServerUpdate_UserOnlineStatus{
+				UserOnlineStatus: &protos.UserStatusChange{Changed: user.proto, Add: true},
+			}})
+		}
+	}
+
+	logging.FromContext(ctx).Info("user unblocked", "blocked_identity", identity)
+	return &protos.Empty{}, nil
+}
+
+// ListBlocked returns the block list of the caller, online users with their
+// current id and username.
+func (s *GrpcBackend) ListBlocked(ctx context.Context, _ *protos.Empty) (*protos.UserList, error) {
+	clientId, _ := getClientIdFromContext(ctx)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	caller := s.onlineUsers[clientId]
+	if caller == nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	list := s.blocks[caller.identity()]
+	users := make([]*protos.User, 0, len(list))
+	for identity, entry := range list {
+		user := &protos.User{Id: entry.id, Username: entry.username}
+		for _, online := range s.onlineUsers {
+			if online.identity() == identity {
+				user = online.proto
+			}
+		}
+		users = append(users, user)
+	}
+	return &protos.UserList{Users: users}, nil
+}
+
+// dropSessionBlocksLocked forgets a guest session that ended: its own list and
+// the entries of others pointing at it. The session never comes back, and
+// whoever takes the username next is someone else. The caller holds s.mu.
+func (s *GrpcBackend) dropSessionBlocksLocked(identity string) {
+	delete(s.blocks, identity)
+	for blocker, list := range s.blocks {
+		delete(list, identity)
+		if len(list) == 0 {
+			delete(s.blocks, blocker)
+		}
+	}
+}
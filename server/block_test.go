@@ -0,0 +1,183 @@
+package server
+
+import (
+	"chat/protos"
+	"errors"
+	"testing"
+)
+
+func block(t *testing.T, backend *GrpcBackend, blockerId, blockedId string) {
+	t.Helper()
+	if _, err := backend.BlockUser(clientContext(blockerId), &protos.BlockRequest{UserId: blockedId}); err != nil {
+		t.Fatalf("block: %v", err)
+	}
+}
+
+func deregister(t *testing.T, backend *GrpcBackend, clientId string) {
+	t.Helper()
+	if _, err := backend.Deregister(clientContext(clientId), &protos.Empty{}); err != nil {
+		t.Fatalf("deregister: %v", err)
+	}
+}
+
+func sendError(backend *GrpcBackend, senderId, receiverId string) error {
+	_, err := backend.SendDirectMessage(clientContext(senderId), &protos.NewMessage{ReceiverId: receiverId, Message: "hi"})
+	return err
+}
+
+func listed(t *testing.T, backend *GrpcBackend, clientId, username string) bool {
+	t.Helper()
+	list, err := backend.List(clientContext(clientId), &protos.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, user := range list.Users {
+		if user.Username == username {
+			return true
+		}
+	}
+	return false
+}
+
+func blockedIds(t *testing.T, backend *GrpcBackend, clientId string) []string {
+	t.Helper()
+	list, err := backend.ListBlocked(clientContext(clientId), &protos.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]string, 0, len(list.Users))
+	for _, user := range list.Users {
+		ids = append(ids, user.Id)
+	}
+	return ids
+}
+
+func TestAccountBlockSurvivesReconnects(t *testing.T) {
+	backend := newAccountBackend(t, "alice", "mallory")
+	alice := registerAccount(t, backend, "alice")
+	mallory := registerAccount(t, backend, "mallory")
+	block(t, backend, alice, mallory)
+	if err := sendError(backend, mallory, alice); !errors.Is(err, ErrBlocked) {
+		t.Errorf("blocked sender got %v, want ErrBlocked", err)
+	}
+	if listed(t, backend, alice, "mallory") {
+		t.Error("blocked user is listed")
+	}
+
+	// the blocked account comes back with a new id
+	deregister(t, backend, mallory)
+	mallory = registerAccount(t, backend, "mallory")
+	if err := sendError(backend, mallory, alice); !errors.Is(err, ErrBlocked) {
+		t.Errorf("reconnected sender got %v, want ErrBlocked", err)
+	}
+	if listed(t, backend, alice, "mallory") {
+		t.Error("reconnected blocked user is listed")
+	}
+
+	// so does the blocker, which gets its list back
+	deregister(t, backend, alice)
+	alice = registerAccount(t, backend, "alice")
+	if err := sendError(backend, mallory, alice); !errors.Is(err, ErrBlocked) {
+		t.Errorf("sender to the reconnected blocker got %v, want ErrBlocked", err)
+	}
+	if ids := blockedIds(t, backend, alice); len(ids) != 1 || ids[0] != mallory {
+		t.Fatalf("blocked list = %v, want mallory with its current id", ids)
+	}
+
+	if _, err := backend.UnblockUser(clientContext(alice), &protos.BlockRequest{UserId: mallory}); err != nil {
+		t.Fatalf("unblock: %v", err)
+	}
+	if err := sendError(backend, mallory, alice); err != nil {
+		t.Errorf("unblocked sender got %v", err)
+	}
+}
+
+func TestUnblockOfflineAccount(t *testing.T) {
+	backend := newAccountBackend(t, "alice", "mallory")
+	alice := registerAccount(t, backend, "alice")
+	mallory := registerAccount(t, backend, "mallory")
+	block(t, backend, alice, mallory)
+	deregister(t, backend, mallory)
+
+	if _, err := backend.UnblockUser(clientContext(alice), &protos.BlockRequest{UserId: mallory}); err != nil {
+		t.Fatalf("unblock by the last seen id: %v", err)
+	}
+	mallory = registerAccount(t, backend, "mallory")
+	if err := sendError(backend, mallory, alice); err != nil {
+		t.Errorf("unblocked sender got %v", err)
+	}
+}
+
+func TestGuestBlocksEndWithTheSession(t *testing.T) {
+	backend := newTestBackend(t)
+	alice := register(t, backend, "alice")
+	mallory := register(t, backend, "mallory")
+	carol := register(t, backend, "carol")
+	block(t, backend, alice, mallory)
+	block(t, backend, carol, alice)
+
+	// whoever takes the freed username is someone else
+	deregister(t, backend, mallory)
+	mallory = register(t, backend, "mallory")
+	if err := sendError(backend, mallory, alice); err != nil {
+		t.Errorf("new mallory got %v", err)
+	}
+	if len(blockedIds(t, backend, alice)) != 0 {
+		t.Error("entry of the ended session kept")
+	}
+
+	// and doesn't inherit the list, nor the blocks against the name
+	deregister(t, backend, alice)
+	alice = register(t, backend, "alice")
+	if err := sendError(backend, mallory, alice); err != nil {
+		t.Errorf("sender to the new alice got %v", err)
+	}
+	if err := sendError(backend, alice, carol); err != nil {
+		t.Errorf("new alice got %v from carol", err)
+	}
+	if len(blockedIds(t, backend, alice)) != 0 {
+		t.Error("new alice inherited a block list")
+	}
+	if len(backend.blocks) != 0 {
+		t.Errorf("blocks left behind: %v", backend.blocks)
+	}
+}
+
+func TestBlockFollowsRenames(t *testing.T) {
+	backend := newAccountBackend(t, "mallory")
+	alice := register(t, backend, "alice")
+	mallory := registerAccount(t, backend, "mallory")
+	eve := register(t, backend, "eve")
+	block(t, backend, alice, mallory)
+	block(t, backend, alice, eve)
+
+	tests := []struct {
+		name     string
+		clientId string
+		username string
+	}{
+		{"account", mallory, "not-mallory"},
+		{"guest", eve, "not-eve"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := backend.ChangeNickname(clientContext(test.clientId), &protos.NicknameRequest{Username: test.username}); err != nil {
+				t.Fatal(err)
+			}
+			if err := sendError(backend, test.clientId, alice); !errors.Is(err, ErrBlocked) {
+				t.Errorf("renamed sender got %v, want ErrBlocked", err)
+			}
+			if listed(t, backend, alice, test.username) {
+				t.Error("renamed blocked user is listed")
+			}
+		})
+	}
+
+	// the renamed blocker keeps its list
+	if _, err := backend.ChangeNickname(clientContext(alice), &protos.NicknameRequest{Username: "alicia"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sendError(backend, eve, alice); !errors.Is(err, ErrBlocked) {
+		t.Errorf("sender to the renamed blocker got %v, want ErrBlocked", err)
+	}
+}
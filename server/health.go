@@ -0,0 +1,37 @@
+package server
+
+import (
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"strings"
+)
+
+// Subsystems reported through grpc.health.v1. The empty name stands for the
+// server as a whole.
+const (
+	HealthOverall = ""
+	HealthChat    = "RegisterUser"
+	HealthAdmin   = "Admin"
+)
+
+// NewHealthServer creates the health service. The admin subsystem is only
+// serving when an admin token is configured.
+func NewHealthServer(adminEnabled bool) *health.Server {
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(HealthOverall, healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus(HealthChat, healthpb.HealthCheckResponse_SERVING)
+
+	adminStatus := healthpb.HealthCheckResponse_NOT_SERVING
+	if adminEnabled {
+		adminStatus = healthpb.HealthCheckResponse_SERVING
+	}
+	healthServer.SetServingStatus(HealthAdmin, adminStatus)
+	return healthServer
+}
+
+// publicMethod reports whether a route can be called without a client id.
+func publicMethod(fullMethod string) bool {
+	return fullMethod == "/RegisterUser/Register" ||
+		strings.HasPrefix(fullMethod, "/grpc.health.v1.Health/") ||
+		strings.HasPrefix(fullMethod, "/grpc.reflection.")
+}
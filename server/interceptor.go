@@ -1,11 +1,12 @@
 package server
 
 import (
+	"chat/logging"
 	"context"
 	"errors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
-	"log"
+	"log/slog"
 )
 
 func (s *GrpcBackend) validateRequestMetadata(ctx context.Context) (newCtx context.Context, err error) {
@@ -13,7 +14,10 @@ func (s *GrpcBackend) validateRequestMetadata(ctx context.Context) (newCtx conte
 	if ok {
 		clientId := md.Get("client-id")
 		if len(clientId) > 0 {
-			if _, wasThere := s.onlineUsers[clientId[0]]; wasThere {
+			s.mu.RLock()
+			_, wasThere := s.onlineUsers[clientId[0]]
+			s.mu.RUnlock()
+			if wasThere {
 				ctx = context.WithValue(ctx, "client-id", clientId[0])
 				return ctx, nil
 			}
@@ -22,21 +26,49 @@ func (s *GrpcBackend) validateRequestMetadata(ctx context.Context) (newCtx conte
 	return nil, errors.New("verification failed")
 }
 
+// requestLogger attaches a logger carrying the method and client id to the context.
+func requestLogger(ctx context.Context, method string) (context.Context, *slog.Logger) {
+	logger := slog.With("method", method)
+	if clientId, ok := getClientIdFromContext(ctx); ok {
+		logger = logger.With("client_id", clientId)
+	}
+	return logging.WithLogger(ctx, logger), logger
+}
+
 func (s *GrpcBackend) UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
 
 	// no user id required
-	if firstClientRequest := info.FullMethod == "/RegisterUser/Register"; firstClientRequest {
-		log.Println("interceptor: /RegisterUser/Register route allowed")
+	if publicMethod(info.FullMethod) {
+		ctx, logger := requestLogger(ctx, info.FullMethod)
+		logger.Debug("route allowed without client id")
+		return handler(ctx, req)
+	}
+
+	// operator calls carry a token instead of a client id
+	if adminMethod(info.FullMethod) {
+		ctx, logger := requestLogger(ctx, info.FullMethod)
+		if err := s.validateAdminToken(ctx); err != nil {
+			logger.Warn("admin request rejected", "err", err)
+			return nil, err
+		}
+		logger.Info("admin request")
 		return handler(ctx, req)
 	}
 
 	// validate client id
 	newCtx, err := s.validateRequestMetadata(ctx)
-	log.Println("[unary interceptor]", "method:", info.FullMethod, "clientId:", newCtx.Value("client-id"))
 	if err != nil {
+		slog.Warn("request rejected", "method", info.FullMethod, "err", err)
 		return nil, err
 	}
-	return handler(newCtx, req)
+	newCtx, logger := requestLogger(newCtx, info.FullMethod)
+	logger.Debug("unary request")
+
+	resp, err = handler(newCtx, req)
+	if err != nil {
+		logger.Info("unary request failed", "err", err)
+	}
+	return resp, err
 }
 
 type customServerStream struct {
@@ -58,12 +90,19 @@ func (css *customServerStream) SendHeader(md metadata.MD) error {
 }
 
 func (s *GrpcBackend) StreamServerInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	// health watch and reflection
+	if publicMethod(info.FullMethod) {
+		return handler(srv, ss)
+	}
+
 	newCtx, err := s.validateRequestMetadata(ss.Context())
 	if err != nil {
+		slog.Warn("stream rejected", "method", info.FullMethod, "err", err)
 		return err
 	}
 
-	log.Println("[stream interceptor]", "method:", info.FullMethod, "clientId:", newCtx.Value("client-id"))
+	newCtx, logger := requestLogger(newCtx, info.FullMethod)
+	logger.Debug("stream opened")
 
 	md, _ := metadata.FromIncomingContext(ss.Context())
 
@@ -73,7 +112,7 @@ func (s *GrpcBackend) StreamServerInterceptor(srv interface{}, ss grpc.ServerStr
 		md:           md,
 	})
 	if err != nil {
-		log.Printf("Error during streaming: %v", err)
+		logger.Warn("error during streaming", "err", err)
 	}
 	return err
 }
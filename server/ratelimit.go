@@ -0,0 +1,117 @@
+package server
+
+import (
+	"chat/config"
+	"chat/logging"
+	"context"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"math"
+	"sync"
+	"time"
+)
+
+// RetryAfterKey is the trailer telling a limited client how many seconds to wait.
+const RetryAfterKey = "retry-after"
+
+// idleBucketTTL is how long an unused bucket is kept before it is pruned.
+const idleBucketTTL = 10 * time.Minute
+
+type bucketKey struct {
+	clientId string
+	method   string
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter keeps a token bucket per client id and method.
+type RateLimiter struct {
+	mu        sync.Mutex
+	limits    config.RateLimitConfig
+	buckets   map[bucketKey]*tokenBucket
+	lastPrune time.Time
+}
+
+func NewRateLimiter(limits config.RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		limits:  limits,
+		buckets: make(map[bucketKey]*tokenBucket),
+	}
+}
+
+func (l *RateLimiter) limitFor(method string) config.RateLimit {
+	if limit, ok := l.limits.Methods[method]; ok {
+		return limit
+	}
+	return l.limits.Default
+}
+
+// Allow takes a token from the caller's bucket. When the bucket is empty it
+// returns how long to wait for the next token.
+func (l *RateLimiter) Allow(clientId, method string) (bool, time.Duration) {
+	limit := l.limitFor(method)
+	if limit.Rate <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.pruneLocked(now)
+
+	key := bucketKey{clientId: clientId, method: method}
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = bucket
+	}
+
+	// refill since the last call
+	elapsed := now.Sub(bucket.last).Seconds()
+	bucket.tokens = math.Min(float64(limit.Burst), bucket.tokens+elapsed*limit.Rate)
+	bucket.last = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+	missing := 1 - bucket.tokens
+	return false, time.Duration(missing / limit.Rate * float64(time.Second))
+}
+
+func (l *RateLimiter) pruneLocked(now time.Time) {
+	if now.Sub(l.lastPrune) < idleBucketTTL {
+		return
+	}
+	l.lastPrune = now
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.last) > idleBucketTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// UnaryServerInterceptor runs after the client id check, calls without a
+// client id (registration, health, admin) are not limited.
+func (l *RateLimiter) UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	clientId, ok := getClientIdFromContext(ctx)
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	allowed, retryAfter := l.Allow(clientId, info.FullMethod)
+	if !allowed {
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		grpc.SetTrailer(ctx, metadata.Pairs(RetryAfterKey, fmt.Sprint(seconds)))
+		logging.FromContext(ctx).Info("rate limit exceeded", "retry_after", retryAfter)
+		return nil, status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry after %ds", seconds)
+	}
+	return handler(ctx, req)
+}
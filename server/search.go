@@ -0,0 +1,208 @@
+package server
+
+import (
+	"chat/logging"
+	"chat/protos"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 200
+)
+
+// SearchIndex is an inverted index from lower case words to the ids of the
+// messages containing them.
+type SearchIndex struct {
+	mu sync.RWMutex
+	// postings are kept sorted by id for intersect
+	postings map[string][]uint64
+}
+
+func NewSearchIndex() *SearchIndex {
+	return &SearchIndex{postings: make(map[string][]uint64)}
+}
+
+// tokenize splits text into distinct lower case words of letters and digits.
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	seen := make(map[string]bool, len(words))
+	tokens := words[:0]
+	for _, word := range words {
+		if !seen[word] {
+			seen[word] = true
+			tokens = append(tokens, word)
+		}
+	}
+	return tokens
+}
+
+// Add indexes a message. Concurrent senders get their ids from the store
+// before indexing, so ids can arrive out of order and are inserted in place.
+func (i *SearchIndex) Add(message StoredMessage) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, token := range tokenize(message.Text) {
+		postings := i.postings[token]
+		position, found := slices.BinarySearch(postings, message.Id)
+		if !found {
+			i.postings[token] = slices.Insert(postings, position, message.Id)
+		}
+	}
+}
+
+// Lookup returns the ids of messages containing all the words, oldest first.
+func (i *SearchIndex) Lookup(tokens []string) []uint64 {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	// start from the rarest word, the intersection only shrinks
+	lists := make([][]uint64, 0, len(tokens))
+	for _, token := range tokens {
+		postings, ok := i.postings[token]
+		if !ok {
+			return nil
+		}
+		lists = append(lists, postings)
+	}
+	shortest := 0
+	for n, list := range lists {
+		if len(list) < len(lists[shortest]) {
+			shortest = n
+		}
+	}
+
+	result := append([]uint64(nil), lists[shortest]...)
+	for n, list := range lists {
+		if n != shortest {
+			result = intersect(result, list)
+		}
+	}
+	return result
+}
+
+// intersect keeps the ids of a that are also in b, both are sorted.
+func intersect(a, b []uint64) []uint64 {
+	kept := a[:0]
+	j := 0
+	for _, id := range a {
+		for j < len(b) && b[j] < id {
+			j++
+		}
+		if j < len(b) && b[j] == id {
+			kept = append(kept, id)
+		}
+	}
+	return kept
+}
+
+// record stores a delivered message and indexes it. Failures are only logged,
+// the receiver already has the message.
+func (s *GrpcBackend) record(ctx context.Context, sender, receiver *User, message *protos.DirectMessage) {
+	s.mu.RLock()
+	stored := StoredMessage{
+		Sender:           sender.proto.Username,
+		Receiver:         receiver.proto.Username,
+		SenderIdentity:   sender.identity(),
+		ReceiverIdentity: receiver.identity(),
+		Text:             message.Message,
+		Time:             message.Time.AsTime(),
+	}
+	s.mu.RUnlock()
+	stored, err := s.messages.Append(stored)
+	if err != nil {
+		logging.FromContext(ctx).Error("message not stored", "err", err)
+		return
+	}
+	s.index.Add(stored)
+}
+
+func (s *GrpcBackend) SearchMessages(ctx context.Context, request *protos.SearchRequest) (*protos.SearchResponse, error) {
+	clientId, _ := getClientIdFromContext(ctx)
+	// the peer filter also finds a renamed peer that is online
+	s.mu.RLock()
+	caller, online := s.onlineUsers[clientId]
+	var identity, peerIdentity string
+	if online {
+		identity = caller.identity()
+		for _, user := range s.onlineUsers {
+			if request.Peer != "" && user.proto.Username == request.Peer {
+				peerIdentity = user.identity()
+			}
+		}
+	}
+	s.mu.RUnlock()
+	if !online {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	tokens := tokenize(request.Query)
+	if len(tokens) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "query has no words")
+	}
+	limit := int(request.Limit)
+	switch {
+	case limit < 0:
+		return nil, status.Error(codes.InvalidArgument, "limit can't be negative")
+	case limit == 0:
+		limit = defaultSearchLimit
+	case limit > maxSearchLimit:
+		limit = maxSearchLimit
+	}
+
+	ids := s.index.Lookup(tokens)
+	results := make([]*protos.SearchResult, 0, min(limit, len(ids)))
+	// newest first
+	for n := len(ids) - 1; n >= 0 && len(results) < limit; n-- {
+		message, ok := s.messages.Get(ids[n])
+		if !ok || !matchesSearch(message, identity, peerIdentity, request) {
+			continue
+		}
+		results = append(results, &protos.SearchResult{
+			Sender:   message.Sender,
+			Receiver: message.Receiver,
+			Message:  message.Text,
+			Time:     timestamppb.New(message.Time),
+		})
+	}
+
+	logging.FromContext(ctx).Debug("messages searched", "words", len(tokens), "candidates", len(ids), "results", len(results))
+	return &protos.SearchResponse{Results: results}, nil
+}
+
+// matchesSearch applies the filters of a request, only conversations of the
+// caller's identity are ever matched. The peer filter matches the username a
+// message was sent with or the identity of the online peer of that name.
+func matchesSearch(message StoredMessage, identity, peerIdentity string, request *protos.SearchRequest) bool {
+	var peer, storedPeerIdentity string
+	switch identity {
+	case message.SenderIdentity:
+		peer, storedPeerIdentity = message.Receiver, message.ReceiverIdentity
+	case message.ReceiverIdentity:
+		peer, storedPeerIdentity = message.Sender, message.SenderIdentity
+	default:
+		return false
+	}
+	if request.Peer != "" && request.Peer != peer && (peerIdentity == "" || peerIdentity != storedPeerIdentity) {
+		return false
+	}
+	if request.From != nil && message.Time.Before(request.From.AsTime()) {
+		return false
+	}
+	if request.To != nil && message.Time.After(request.To.AsTime()) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,226 @@
+package server
+
+import (
+	"chat/config"
+	"chat/protos"
+	"context"
+	"fmt"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func newTestBackend(t *testing.T) *GrpcBackend {
+	t.Helper()
+	backend, err := NewGrpcImplementation(config.Default().Server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { backend.Close() })
+	return backend
+}
+
+func TestSearchIndexOutOfOrderAdds(t *testing.T) {
+	index := NewSearchIndex()
+	for _, id := range []uint64{5, 1, 4, 3, 2, 3} {
+		text := "alpha"
+		if id%2 == 1 {
+			text = "alpha beta"
+		}
+		index.Add(StoredMessage{Id: id, Text: text})
+	}
+
+	tests := []struct {
+		tokens []string
+		want   []uint64
+	}{
+		{[]string{"alpha"}, []uint64{1, 2, 3, 4, 5}},
+		{[]string{"alpha", "beta"}, []uint64{1, 3, 5}},
+		{[]string{"beta", "alpha"}, []uint64{1, 3, 5}},
+		{[]string{"gamma"}, nil},
+	}
+	for _, test := range tests {
+		if got := index.Lookup(test.tokens); !slices.Equal(got, test.want) {
+			t.Errorf("Lookup(%v) = %v, want %v", test.tokens, got, test.want)
+		}
+	}
+}
+
+func TestRecordConcurrentSenders(t *testing.T) {
+	backend := newTestBackend(t)
+	const senders, messages = 64, 200
+	// the senders must run in parallel to interleave
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(max(4, runtime.GOMAXPROCS(0))))
+
+	alice := &User{proto: &protos.User{Id: "1", Username: "alice"}}
+	bob := &User{proto: &protos.User{Id: "2", Username: "bob"}}
+	var wg sync.WaitGroup
+	for sender := 0; sender < senders; sender++ {
+		wg.Add(1)
+		go func(sender int) {
+			defer wg.Done()
+			for n := 0; n < messages; n++ {
+				backend.record(context.Background(), alice, bob, &protos.DirectMessage{
+					Message: fmt.Sprintf("alpha beta %d %d", sender, n),
+					Time:    timestamppb.Now(),
+				})
+			}
+		}(sender)
+	}
+	wg.Wait()
+
+	for _, token := range []string{"alpha", "beta"} {
+		postings := backend.index.postings[token]
+		if !slices.IsSorted(postings) {
+			t.Errorf("postings of %q are not sorted", token)
+		}
+	}
+	if got := len(backend.index.Lookup([]string{"alpha", "beta"})); got != senders*messages {
+		t.Errorf("found %d messages, want %d", got, senders*messages)
+	}
+}
+
+// clientContext carries the client id like the metadata interceptor does.
+func clientContext(clientId string) context.Context {
+	return context.WithValue(context.Background(), "client-id", clientId)
+}
+
+func register(t *testing.T, backend *GrpcBackend, username string) string {
+	t.Helper()
+	user, err := backend.Register(context.Background(), &protos.RegisterRequest{Username: username})
+	if err != nil {
+		t.Fatalf("register %s: %v", username, err)
+	}
+	return user.Id
+}
+
+// newAccountBackend starts a backend where the usernames are accounts with
+// the password "secret".
+func newAccountBackend(t *testing.T, usernames ...string) *GrpcBackend {
+	t.Helper()
+	hash, err := HashPassword("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Default().Server
+	cfg.Accounts = make(map[string]string, len(usernames))
+	for _, username := range usernames {
+		cfg.Accounts[username] = hash
+	}
+	backend, err := NewGrpcImplementation(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { backend.Close() })
+	return backend
+}
+
+func registerAccount(t *testing.T, backend *GrpcBackend, username string) string {
+	t.Helper()
+	user, err := backend.Register(context.Background(), &protos.RegisterRequest{Username: username, Password: "secret"})
+	if err != nil {
+		t.Fatalf("register %s: %v", username, err)
+	}
+	return user.Id
+}
+
+func send(t *testing.T, backend *GrpcBackend, senderId, receiverId, text string) {
+	t.Helper()
+	if _, err := backend.SendDirectMessage(clientContext(senderId), &protos.NewMessage{ReceiverId: receiverId, Message: text}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+}
+
+func searchCount(t *testing.T, backend *GrpcBackend, clientId, query, peer string) int {
+	t.Helper()
+	response, err := backend.SearchMessages(clientContext(clientId), &protos.SearchRequest{Query: query, Peer: peer})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	return len(response.Results)
+}
+
+func TestSearchUsernameIsNotAnIdentity(t *testing.T) {
+	backend := newTestBackend(t)
+	alice := register(t, backend, "alice")
+	bob := register(t, backend, "bob")
+	send(t, backend, alice, bob, "secret plan")
+
+	if got := searchCount(t, backend, alice, "secret", ""); got != 1 {
+		t.Fatalf("alice found %d messages, want 1", got)
+	}
+
+	// the freed username doesn't come with the history
+	if _, err := backend.Deregister(clientContext(alice), &protos.Empty{}); err != nil {
+		t.Fatal(err)
+	}
+	mallory := register(t, backend, "alice")
+	if got := searchCount(t, backend, mallory, "secret", ""); got != 0 {
+		t.Errorf("new alice found %d messages of the previous one", got)
+	}
+
+	// nor does renaming to a username of the conversation
+	carol := register(t, backend, "carol")
+	if _, err := backend.ChangeNickname(clientContext(bob), &protos.NicknameRequest{Username: "robert"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backend.ChangeNickname(clientContext(carol), &protos.NicknameRequest{Username: "bob"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := searchCount(t, backend, carol, "secret", ""); got != 0 {
+		t.Errorf("carol renamed to bob found %d messages", got)
+	}
+	if got := searchCount(t, backend, bob, "secret", ""); got != 1 {
+		t.Errorf("renamed bob found %d messages, want 1", got)
+	}
+}
+
+func TestSearchFollowsAccounts(t *testing.T) {
+	backend := newAccountBackend(t, "alice")
+	alice := registerAccount(t, backend, "alice")
+	bob := register(t, backend, "bob")
+	send(t, backend, alice, bob, "quarterly numbers")
+	if _, err := backend.ChangeNickname(clientContext(alice), &protos.NicknameRequest{Username: "al"}); err != nil {
+		t.Fatal(err)
+	}
+	send(t, backend, bob, alice, "numbers look fine")
+
+	// a new session of the account keeps the history
+	if _, err := backend.Deregister(clientContext(alice), &protos.Empty{}); err != nil {
+		t.Fatal(err)
+	}
+	alice = registerAccount(t, backend, "alice")
+
+	tests := []struct {
+		name string
+		peer string
+		want int
+	}{
+		{"all", "", 2},
+		{"peer by username", "bob", 2},
+		{"other peer", "carol", 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := searchCount(t, backend, alice, "numbers", test.peer); got != test.want {
+				t.Errorf("found %d messages, want %d", got, test.want)
+			}
+		})
+	}
+
+	// bob finds the account as peer by its current username
+	if got := searchCount(t, backend, bob, "numbers", "alice"); got != 2 {
+		t.Errorf("bob found %d messages with alice, want 2", got)
+	}
+
+	// the guest's history ends with its session
+	if _, err := backend.Deregister(clientContext(bob), &protos.Empty{}); err != nil {
+		t.Fatal(err)
+	}
+	bob = register(t, backend, "bob")
+	if got := searchCount(t, backend, bob, "numbers", ""); got != 0 {
+		t.Errorf("new bob found %d messages of the previous one", got)
+	}
+}
@@ -1,22 +1,80 @@
 package server
 
 import (
+	"chat/config"
+	"chat/logging"
 	"chat/protos"
 	"context"
 	"errors"
 	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
-	"log"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type User struct {
-	proto                *protos.User
-	sendMessage          chan *protos.DirectMessage
-	sendUserStatusUpdate chan *protos.UserStatusChange
+	proto   *protos.User
+	updates chan *protos.ServerUpdate
+	// disconnected is closed when the user leaves, which ends the update stream
+	disconnected chan struct{}
+
+	registered    time.Time
+	streamStarted time.Time
+	// account is the server.accounts username logged in to, empty for guests
+	account string
+}
+
+// identity is who the user is beyond its username: the account, or for
+// guests the session. A free username can be registered by anyone, so it
+// never identifies a guest.
+func (u *User) identity() string {
+	if u.account != "" {
+		return "account:" + u.account
+	}
+	return "session:" + u.proto.Id
+}
+
+// enqueue adds an update without blocking, a full queue drops it.
+func (u *User) enqueue(update *protos.ServerUpdate) bool {
+	select {
+	case u.updates <- update:
+		return true
+	default:
+		return false
+	}
+}
+
+type serverStats struct {
+	started           time.Time
+	registrations     atomic.Int64
+	messagesForwarded atomic.Int64
+	messagesDropped   atomic.Int64
 }
 
 type GrpcBackend struct {
+	mu          sync.RWMutex
 	onlineUsers map[string]*User
+	// banned maps a username to the ban reason
+	banned map[string]string
+	// accounts maps usernames that need a password to its hash
+	accounts map[string]string
+	// blocks maps the identity of a user to the users it blocked, by
+	// identity. Lists of accounts outlive their sessions.
+	blocks map[string]map[string]blockedUser
+
+	queueSize    int
+	queueTimeout time.Duration
+	adminToken   string
+
+	messages MessageStore
+	index    *SearchIndex
+
+	stats serverStats
 }
 
 func (s *GrpcBackend) Deregister(ctx context.Context, _ *protos.Empty) (*protos.Empty, error) {
@@ -25,34 +83,107 @@ func (s *GrpcBackend) Deregister(ctx context.Context, _ *protos.Empty) (*protos.
 		return &protos.Empty{}, errors.New("user not found")
 	}
 
-	log.Printf("user <%s> deleted\n", s.onlineUsers[clientId].proto.Username)
-	userToDelete := s.onlineUsers[clientId]
+	userToDelete, ok := s.removeUser(clientId)
+	if !ok {
+		return &protos.Empty{}, errors.New("user not found")
+	}
+	logging.FromContext(ctx).Info("user deleted", "username", userToDelete.proto.Username)
 
-	for _, user := range s.onlineUsers {
-		user.sendUserStatusUpdate <- &protos.UserStatusChange{
+	return &protos.Empty{}, nil
+}
+
+// removeUser takes the user offline, closes its stream and notifies the others.
+func (s *GrpcBackend) removeUser(clientId string) (*User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	userToDelete, ok := s.onlineUsers[clientId]
+	if !ok {
+		return nil, false
+	}
+	delete(s.onlineUsers, clientId)
+	close(userToDelete.disconnected)
+	if userToDelete.account == "" {
+		s.dropSessionBlocksLocked(userToDelete.identity())
+	}
+
+	s.broadcastLocked(&protos.ServerUpdate{Content: &protos.ServerUpdate_UserOnlineStatus{
+		UserOnlineStatus: &protos.UserStatusChange{
 			Changed: &protos.User{
 				Id:       userToDelete.proto.Id,
 				Username: userToDelete.proto.Username,
 			},
 			Add: false,
-		}
-	}
+		},
+	}}, userToDelete)
 
-	delete(s.onlineUsers, clientId)
+	return userToDelete, true
+}
 
-	return &protos.Empty{}, nil
+// broadcastLocked queues an update about a user for everyone else, users who
+// blocked it don't get it. Updates about nobody, such as announcements, go to
+// everyone. The caller holds s.mu.
+func (s *GrpcBackend) broadcastLocked(update *protos.ServerUpdate, about *User) {
+	for _, user := range s.onlineUsers {
+		if about != nil && (user == about || s.blockedLocked(user, about)) {
+			continue
+		}
+		if !user.enqueue(update) {
+			s.stats.messagesDropped.Add(1)
+			slog.Warn("update queue full, update dropped", "client_id", user.proto.Id)
+		}
+	}
 }
 
-func NewGrpcImplementation() *GrpcBackend {
-	gb := &GrpcBackend{onlineUsers: make(map[string]*User, 20)}
+func NewGrpcImplementation(cfg config.ServerConfig) (*GrpcBackend, error) {
+	if err := validateAccounts(cfg.Accounts); err != nil {
+		return nil, err
+	}
+	messages, err := NewMessageStore(cfg.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	gb := &GrpcBackend{
+		onlineUsers:  make(map[string]*User, 20),
+		banned:       make(map[string]string),
+		blocks:       make(map[string]map[string]blockedUser),
+		accounts:     cfg.Accounts,
+		queueSize:    cfg.QueueSize,
+		queueTimeout: cfg.QueueTimeout,
+		adminToken:   cfg.AdminToken,
+		messages:     messages,
+		index:        NewSearchIndex(),
+		stats:        serverStats{started: time.Now()},
+	}
 	//gb.Register(context.Background(), &protos.RegisterRequest{
 	//	Username: "bot-always available",
 	//})
 
-	return gb
+	// the index lives in memory only
+	messages.Each(gb.index.Add)
+
+	return gb, nil
+}
+
+// Close releases the message store.
+func (s *GrpcBackend) Close() error {
+	return s.messages.Close()
 }
 
 func (s *GrpcBackend) Register(ctx context.Context, request *protos.RegisterRequest) (*protos.User, error) {
+	// accounts don't change after start, the slow check runs unlocked
+	if entry, hasAccount := s.accounts[request.Username]; hasAccount && !checkPassword(entry, request.Password) {
+		logging.FromContext(ctx).Warn("wrong password", "username", request.Username)
+		return nil, status.Error(codes.Unauthenticated, "wrong username or password")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if reason, isBanned := s.banned[request.Username]; isBanned {
+		return nil, status.Errorf(codes.PermissionDenied, "username is banned: %s", reason)
+	}
 	for _, user := range s.onlineUsers {
 		if user.proto.Username == request.Username {
 			return nil, errors.New("username is already taken")
@@ -64,35 +195,44 @@ func (s *GrpcBackend) Register(ctx context.Context, request *protos.RegisterRequ
 			Id:       uuid.NewString(),
 			Username: request.Username,
 		},
-		sendMessage:          make(chan *protos.DirectMessage, 100),
-		sendUserStatusUpdate: make(chan *protos.UserStatusChange, 100),
+		updates:      make(chan *protos.ServerUpdate, s.queueSize),
+		disconnected: make(chan struct{}),
+		registered:   time.Now(),
+	}
+	if _, hasAccount := s.accounts[request.Username]; hasAccount {
+		user.account = request.Username
 	}
 
 	s.onlineUsers[user.proto.Id] = user
-
-	// create notification
-	update := &protos.UserStatusChange{
-		Changed: &protos.User{
-			Id:       user.proto.Id,
-			Username: user.proto.Username,
-		},
-		Add: true,
-	}
+	s.stats.registrations.Add(1)
+	logging.FromContext(ctx).Info("user registered", "client_id", user.proto.Id, "username", user.proto.Username)
 
 	// update users' lists
-	for _, otherUsers := range s.onlineUsers {
-		if otherUsers.proto.Id == update.Changed.Id {
-			continue
-		}
-		otherUsers.sendUserStatusUpdate <- update
-	}
+	s.broadcastLocked(&protos.ServerUpdate{Content: &protos.ServerUpdate_UserOnlineStatus{
+		UserOnlineStatus: &protos.UserStatusChange{
+			Changed: &protos.User{
+				Id:       user.proto.Id,
+				Username: user.proto.Username,
+			},
+			Add: true,
+		},
+	}}, user)
 
 	return user.proto, nil
 }
 
-func (s *GrpcBackend) List(context.Context, *protos.Empty) (*protos.UserList, error) {
+func (s *GrpcBackend) List(ctx context.Context, _ *protos.Empty) (*protos.UserList, error) {
+	clientId, _ := getClientIdFromContext(ctx)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	caller := s.onlineUsers[clientId]
 	allUsers := make([]*protos.User, 0, 20)
 	for _, v := range s.onlineUsers {
+		if caller != nil && s.blockedLocked(caller, v) {
+			continue
+		}
 		allUsers = append(allUsers, v.proto)
 	}
 
@@ -110,26 +250,54 @@ func (s *GrpcBackend) SendDirectMessage(ctx context.Context, request *protos.New
 	// parse all request data
 	sender, _ := getClientIdFromContext(ctx)
 	receiver := request.ReceiverId
-	if _, r := s.onlineUsers[receiver]; !r {
+
+	s.mu.RLock()
+	messageSender, senderOnline := s.onlineUsers[sender]
+	messageReceiver, receiverOnline := s.onlineUsers[receiver]
+	senderBlocked := receiverOnline && senderOnline && s.blockedLocked(messageReceiver, messageSender)
+	// ChangeNickname and SetStatus replace proto under the lock
+	var senderName, receiverName string
+	if senderOnline && receiverOnline {
+		senderName, receiverName = messageSender.proto.Username, messageReceiver.proto.Username
+	}
+	s.mu.RUnlock()
+	if !receiverOnline {
 		return nil, errors.New("receiver not found")
 	}
-	message := request.Message
-
-	if _, ok := s.onlineUsers[receiver]; !ok {
-		log.Println("message receiver does not exist")
+	if !senderOnline {
+		return nil, errors.New("sender not found")
 	}
+	if senderBlocked {
+		return nil, ErrBlocked
+	}
+	message := request.Message
 
-	log.Printf("[%s]->[%s], message: '%s'\n", s.onlineUsers[sender].proto.Username, s.onlineUsers[receiver].proto.Username, message)
+	logging.FromContext(ctx).Debug("forwarding direct message",
+		"sender", senderName,
+		"receiver", receiverName,
+		"length", len(message))
 	// forward message
-	messageReceiver := s.onlineUsers[receiver]
 	newMessage := &protos.DirectMessage{
 		SenderId: sender,
 		Message:  message,
 		Time:     timestamppb.Now(),
 	}
+	update := &protos.ServerUpdate{Content: &protos.ServerUpdate_IncomingMessage{IncomingMessage: newMessage}}
 
-	messageReceiver.sendMessage <- newMessage
-	return newMessage, nil
+	// don't block the sender forever on a stalled receiver
+	timeout := time.NewTimer(s.queueTimeout)
+	defer timeout.Stop()
+	select {
+	case messageReceiver.updates <- update:
+		s.stats.messagesForwarded.Add(1)
+		s.record(ctx, messageSender, messageReceiver, newMessage)
+		return newMessage, nil
+	case <-messageReceiver.disconnected:
+		return nil, errors.New("receiver not found")
+	case <-timeout.C:
+		s.stats.messagesDropped.Add(1)
+		return nil, status.Error(codes.Unavailable, "receiver queue is full")
+	}
 }
 
 func (s *GrpcBackend) GetUpdates(_ *protos.SubscriptionRequest, server protos.RegisterUser_GetUpdatesServer) error {
@@ -138,25 +306,130 @@ func (s *GrpcBackend) GetUpdates(_ *protos.SubscriptionRequest, server protos.Re
 		return errors.New("client id not provided")
 	}
 
-	user := s.onlineUsers[clientId]
+	s.mu.Lock()
+	user, online := s.onlineUsers[clientId]
+	if online {
+		user.streamStarted = time.Now()
+	}
+	s.mu.Unlock()
+	if !online {
+		return errors.New("user not found")
+	}
+	defer func() {
+		s.mu.Lock()
+		user.streamStarted = time.Time{}
+		s.mu.Unlock()
+	}()
 
-	// stream all notifications from the buffered channels
+	// stream all notifications from the buffered queue
 	for {
-		serverUpdate := &protos.ServerUpdate{}
 		select {
+		case update := <-user.updates:
+			if err := server.Send(update); err != nil {
+				return err
+			}
+
+		case <-user.disconnected:
+			// flush what was queued before leaving, e.g. a kick notice
+			for {
+				select {
+				case update := <-user.updates:
+					if err := server.Send(update); err != nil {
+						return err
+					}
+				default:
+					logging.FromContext(server.Context()).Info("stream closed, user will not receive new messages")
+					return nil
+				}
+			}
 
-		case message := <-user.sendMessage:
-			serverUpdate.Content = &protos.ServerUpdate_IncomingMessage{IncomingMessage: message}
+		case <-server.Context().Done():
+			return server.Context().Err()
+		}
+	}
+}
+
+// maxStatusLength keeps status messages to a single line in the client.
+const maxStatusLength = 120
+
+func (s *GrpcBackend) SetStatus(ctx context.Context, request *protos.StatusRequest) (*protos.User, error) {
+	clientId, _ := getClientIdFromContext(ctx)
+	if len(request.Status) > maxStatusLength {
+		return nil, status.Errorf(codes.InvalidArgument, "status is longer than %d bytes", maxStatusLength)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, online := s.onlineUsers[clientId]
+	if !online {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	// replace instead of mutating, the old value may still be serialized
+	user.proto = &protos.User{
+		Id:          user.proto.Id,
+		Username:    user.proto.Username,
+		Status:      request.Status,
+		DisplayName: user.proto.DisplayName,
+	}
+	s.broadcastLocked(&protos.ServerUpdate{Content: &protos.ServerUpdate_UserOnlineStatus{
+		UserOnlineStatus: &protos.UserStatusChange{Changed: user.proto, Add: true},
+	}}, user)
+
+	logging.FromContext(ctx).Info("status changed", "status", request.Status)
+	return user.proto, nil
+}
+
+// maxNameLength bounds the usernames and display names set by ChangeNickname.
+const maxNameLength = 32
+
+// ChangeNickname renames the caller, the id and with it the conversations
+// stay. Banned usernames and those of accounts can't be taken.
+func (s *GrpcBackend) ChangeNickname(ctx context.Context, request *protos.NicknameRequest) (*protos.User, error) {
+	clientId, _ := getClientIdFromContext(ctx)
+	username := strings.TrimSpace(request.Username)
+	displayName := strings.TrimSpace(request.DisplayName)
+	if username == "" {
+		return nil, status.Error(codes.InvalidArgument, "username is empty")
+	}
+	if len(username) > maxNameLength || len(displayName) > maxNameLength {
+		return nil, status.Errorf(codes.InvalidArgument, "names are limited to %d bytes", maxNameLength)
+	}
 
-		case changeUserList := <-user.sendUserStatusUpdate:
-			serverUpdate.Content = &protos.ServerUpdate_UserOnlineStatus{UserOnlineStatus: changeUserList}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-			if changeUserList.Changed.Id == clientId {
-				log.Printf("user: <%s> will not receive new messages\n", changeUserList.Changed.Username)
-				return nil
+	user, online := s.onlineUsers[clientId]
+	if !online {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	previous := user.proto.Username
+	if username != previous {
+		if _, isBanned := s.banned[username]; isBanned {
+			return nil, status.Error(codes.PermissionDenied, "username is banned")
+		}
+		if _, hasAccount := s.accounts[username]; hasAccount && user.account != username {
+			return nil, status.Error(codes.PermissionDenied, "username belongs to an account")
+		}
+		for _, other := range s.onlineUsers {
+			if other.proto.Username == username {
+				return nil, status.Error(codes.AlreadyExists, "username is already taken")
 			}
 		}
+	}
 
-		server.Send(serverUpdate)
+	// replace instead of mutating, the old value may still be serialized
+	user.proto = &protos.User{
+		Id:          user.proto.Id,
+		Username:    username,
+		Status:      user.proto.Status,
+		DisplayName: displayName,
 	}
+	s.broadcastLocked(&protos.ServerUpdate{Content: &protos.ServerUpdate_ProfileChanged{
+		ProfileChanged: &protos.ProfileChanged{Changed: user.proto, PreviousUsername: previous},
+	}}, user)
+
+	logging.FromContext(ctx).Info("profile changed", "previous_username", previous, "username", username, "display_name", displayName)
+	return user.proto, nil
 }
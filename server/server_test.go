@@ -0,0 +1,57 @@
+package server
+
+import (
+	"chat/protos"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// drain empties the update queue of a user until the test ends.
+func drain(t *testing.T, backend *GrpcBackend, clientId string) {
+	backend.mu.RLock()
+	user := backend.onlineUsers[clientId]
+	backend.mu.RUnlock()
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+	go func() {
+		for {
+			select {
+			case <-user.updates:
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+func TestSendDuringProfileChanges(t *testing.T) {
+	backend := newTestBackend(t)
+	const changes = 5000
+	// the changes must run in parallel with the sends to race
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(max(4, runtime.GOMAXPROCS(0))))
+
+	alice := register(t, backend, "alice")
+	bob := register(t, backend, "bob")
+	drain(t, backend, alice)
+	drain(t, backend, bob)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for n := 0; n < changes; n++ {
+			backend.ChangeNickname(clientContext(alice), &protos.NicknameRequest{Username: fmt.Sprintf("alice-%d", n)})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for n := 0; n < changes; n++ {
+			backend.SetStatus(clientContext(bob), &protos.StatusRequest{Status: fmt.Sprint(n)})
+		}
+	}()
+	for n := 0; n < changes; n++ {
+		send(t, backend, alice, bob, "hi")
+	}
+	wg.Wait()
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"bufio"
+	"chat/config"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// StoredMessage is a delivered direct message. Sender and Receiver are the
+// usernames when it was sent, the identities decide who may search it, see
+// User.identity.
+type StoredMessage struct {
+	Id               uint64    `json:"id"`
+	Sender           string    `json:"sender"`
+	Receiver         string    `json:"receiver"`
+	SenderIdentity   string    `json:"sender_identity"`
+	ReceiverIdentity string    `json:"receiver_identity"`
+	Text             string    `json:"text"`
+	Time             time.Time `json:"time"`
+}
+
+// MessageStore keeps the message history, ids start at 1 and grow with every
+// appended message.
+type MessageStore interface {
+	Append(message StoredMessage) (StoredMessage, error)
+	Get(id uint64) (StoredMessage, bool)
+	// Each visits all messages, oldest first.
+	Each(visit func(StoredMessage))
+	Close() error
+}
+
+// NewMessageStore opens the store selected by the storage config.
+func NewMessageStore(storage config.StorageConfig) (MessageStore, error) {
+	switch storage.Backend {
+	case config.StorageMemory:
+		return newMemoryStore(), nil
+	case config.StorageFile:
+		return newFileStore(storage.Path)
+	default:
+		return nil, fmt.Errorf("unknown server storage backend %q", storage.Backend)
+	}
+}
+
+type memoryStore struct {
+	mu       sync.RWMutex
+	messages []StoredMessage
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{messages: make([]StoredMessage, 0, 100)}
+}
+
+func (m *memoryStore) Append(message StoredMessage) (StoredMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	message.Id = uint64(len(m.messages)) + 1
+	m.messages = append(m.messages, message)
+	return message, nil
+}
+
+func (m *memoryStore) Get(id uint64) (StoredMessage, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if id == 0 || id > uint64(len(m.messages)) {
+		return StoredMessage{}, false
+	}
+	return m.messages[id-1], true
+}
+
+func (m *memoryStore) Each(visit func(StoredMessage)) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, message := range m.messages {
+		visit(message)
+	}
+}
+
+func (m *memoryStore) Close() error {
+	return nil
+}
+
+// fileStore appends messages to a JSON lines file and serves reads from
+// memory, the file is read once when the server starts.
+type fileStore struct {
+	*memoryStore
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// maxStoredLine bounds a single message line in the store file.
+const maxStoredLine = 1 << 20
+
+func newFileStore(path string) (*fileStore, error) {
+	store := &fileStore{memoryStore: newMemoryStore()}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open message store: %w", err)
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStoredLine)
+	line := 0
+	for scanner.Scan() {
+		line++
+		var message StoredMessage
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			// a crash can leave a partly written last line behind
+			slog.Warn("skipping unreadable stored message", "path", path, "line", line, "err", err)
+			continue
+		}
+		store.memoryStore.Append(message)
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("read message store: %w", err)
+	}
+
+	store.file = file
+	store.encoder = json.NewEncoder(file)
+	slog.Info("message store loaded", "path", path, "messages", len(store.messages))
+	return store, nil
+}
+
+func (f *fileStore) Append(message StoredMessage) (StoredMessage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.memoryStore.mu.RLock()
+	message.Id = uint64(len(f.messages)) + 1
+	f.memoryStore.mu.RUnlock()
+
+	if err := f.encoder.Encode(message); err != nil {
+		return StoredMessage{}, fmt.Errorf("write message store: %w", err)
+	}
+	return f.memoryStore.Append(message)
+}
+
+func (f *fileStore) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return errors.Join(f.file.Sync(), f.file.Close())
+}